@@ -0,0 +1,182 @@
+package command
+
+import (
+	"fmt"
+	"go/types"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/utisam/gogtok/query"
+)
+
+type accessorsField struct {
+	Name   string
+	Type   string
+	Zero   string
+	Getter string
+	Setter string
+}
+
+type accessorsTemplateData struct {
+	Type   string
+	Fields []*accessorsField
+}
+
+const accessorsTemplate = `{{$t := .Type -}}
+{{range .Fields -}}
+{{if .Getter}}
+// {{.Getter}} returns {{.Name}} or the zero value if x is nil.
+func (x *{{$t}}) {{.Getter}}() {{.Type}} {
+	if x != nil {
+		return x.{{.Name}}
+	}
+	return {{.Zero}}
+}
+{{end -}}
+{{if .Setter}}
+// {{.Setter}} sets {{.Name}}.
+func (x *{{$t}}) {{.Setter}}(v {{.Type}}) {
+	x.{{.Name}} = v
+}
+{{end -}}
+{{end -}}
+`
+
+// parseAccessorTag parses the tag such as "get,set".
+func parseAccessorTag(tag string) (getter, setter bool, err error) {
+	if tag == "-" {
+		return false, false, nil
+	}
+	for _, item := range strings.Split(tag, ",") {
+		switch item {
+		case "get":
+			getter = true
+		case "set":
+			setter = true
+		default:
+			return false, false, fmt.Errorf("unknown accessor: %s", item)
+		}
+	}
+	return getter, setter, nil
+}
+
+// methodExists reports whether the field or method of the name is declared except in files to be replaced.
+func (o *generatorOptions) methodExists(pkg *query.Package, obj *types.TypeName, name string) bool {
+	found, _, _ := types.LookupFieldOrMethod(types.NewPointer(obj.Type()), true, pkg.Types, name)
+	return found != nil && !o.replaces(pkg, found, obj.Name())
+}
+
+func newAccessors(c *config) *cobra.Command {
+	opts := &generatorOptions{config: c}
+	fieldPattern := ""
+	setters := false
+	unexported := false
+	tagKey := "accessor"
+
+	cmd := &cobra.Command{
+		Use:   "accessors TYPE [package]",
+		Short: "Generate getters and setters of fields of the struct",
+		Long: `Generate getters and setters of fields of the struct.
+
+Getters Get<FIELD> return the zero value if the receiver is nil like getters of protocol buffers.
+Setters Set<FIELD> are generated with --setters.
+Exported fields are selected by --fields, and unexported ones are also selected with --unexported.
+The tag overrides them:
+  accessor:"-"                Skip the field
+  accessor:"get,set"          Generate the getter and the setter
+Accessors are not generated if methods or fields of the same names already exist
+except in the output file and files generated by this command for the type.
+Generation fails if fields such as foo and Foo have accessors of the same name.`,
+		Example: `  gogtok accessors Config --fields '^[A-Z]' -o config_accessors.go`,
+		Args:    cobra.RangeArgs(1, 2),
+		RunE: func(_ *cobra.Command, args []string) error {
+			typeName := args[0]
+			dir := "."
+			if len(args) > 1 {
+				dir = args[1]
+			}
+
+			re, err := regexp.Compile(fieldPattern)
+			if err != nil {
+				return err
+			}
+
+			pkg, obj, err := opts.lookupType(dir, typeName)
+			if err != nil {
+				return err
+			}
+			st, ok := obj.Type().Underlying().(*types.Struct)
+			if !ok {
+				return fmt.Errorf("not a struct: %s", typeName)
+			}
+
+			data := &accessorsTemplateData{Type: typeName}
+			imports := newGeneratorImports(pkg.Types.Path())
+			fields := map[string]string{}
+			accessor := func(name, fieldName string) (string, error) {
+				if opts.methodExists(pkg, obj, name) {
+					logrus.WithField("type", typeName).Debugf("%s already exists", name)
+					return "", nil
+				}
+				if other, ok := fields[name]; ok {
+					return "", fmt.Errorf("%s is generated for both %s and %s", name, other, fieldName)
+				}
+				fields[name] = fieldName
+				return name, nil
+			}
+
+			for i := 0; i < st.NumFields(); i++ {
+				v := st.Field(i)
+				if v.Name() == "_" {
+					continue
+				}
+
+				selected := (v.Exported() || unexported) && re.MatchString(v.Name())
+				getter, setter := selected, selected && setters
+				if tag, ok := reflect.StructTag(st.Tag(i)).Lookup(tagKey); ok {
+					getter, setter, err = parseAccessorTag(tag)
+					if err != nil {
+						return fmt.Errorf("%s.%s: %v", typeName, v.Name(), err)
+					}
+				}
+
+				field := &accessorsField{
+					Name: v.Name(),
+					Type: types.TypeString(v.Type(), imports.qualifier),
+					Zero: zeroValue(v.Type(), imports.qualifier),
+				}
+				if getter {
+					if field.Getter, err = accessor("Get"+upperFirst(v.Name()), v.Name()); err != nil {
+						return err
+					}
+				}
+				if setter {
+					if field.Setter, err = accessor("Set"+upperFirst(v.Name()), v.Name()); err != nil {
+						return err
+					}
+				}
+				if field.Getter != "" || field.Setter != "" {
+					data.Fields = append(data.Fields, field)
+				}
+			}
+
+			body, err := executeTemplate(accessorsTemplate, data)
+			if err != nil {
+				return err
+			}
+			return opts.write(dir, pkg.Types.Name(), body, imports.imports...)
+		},
+	}
+
+	opts.addFlags(cmd)
+	flags := cmd.Flags()
+	flags.StringVar(&fieldPattern, "fields", fieldPattern, "Regular expression to select fields")
+	flags.BoolVar(&setters, "setters", setters, "Generate setters of selected fields")
+	flags.BoolVar(&unexported, "unexported", unexported, "Select unexported fields by --fields too")
+	flags.StringVar(&tagKey, "tag", tagKey, "Key of the tag to control fields")
+
+	return cmd
+}
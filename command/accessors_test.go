@@ -0,0 +1,74 @@
+package command
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestAccessors(t *testing.T) {
+	dir := newTestModule(t, map[string]string{
+		"config.go": `package test
+
+import "time"
+
+type Config struct {
+	Name    string
+	Timeout time.Duration
+	Sub     *Config
+	Tags    []string ` + "`accessor:\"-\"`" + `
+	secret  string   ` + "`accessor:\"get\"`" + `
+	private string
+}
+
+type Pair struct {
+	key string
+	Key string
+}
+
+func (c *Config) GetName() string {
+	return "custom"
+}
+`,
+		"config_test.go": `package test
+
+import "testing"
+
+func TestConfig(t *testing.T) {
+	var c *Config
+	if c.GetSub().GetTimeout() != 0 || c.GetSecret() != "" {
+		t.Error("getters of nil must return zero values")
+	}
+
+	c = &Config{}
+	c.SetSub(&Config{Timeout: 3})
+	if c.GetSub().GetTimeout() != 3 || c.GetName() != "custom" {
+		t.Error("accessors are wrong")
+	}
+}
+`,
+	})
+
+	// Previous outputs written through stdout must be replaced
+	for i := 0; i < 2; i++ {
+		generateStdout(t, dir, "config_gen.go", "accessors", "Config", "--setters")
+	}
+	b, err := ioutil.ReadFile(filepath.Join(dir, "config_gen.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"GetTimeout", "SetSub", "GetSecret"} {
+		if !regexpMatch(`func \(x \*Config\) `+name+`\(`, b) {
+			t.Errorf("%s is not generated:\n%s", name, b)
+		}
+	}
+	for _, name := range []string{"GetName", "GetTags", "GetPrivate", "SetSecret"} {
+		if regexpMatch(`func \(x \*Config\) `+name+`\(`, b) {
+			t.Errorf("%s must not be generated:\n%s", name, b)
+		}
+	}
+
+	generateError(t, dir, "GetKey is generated for both key and Key", "accessors", "Pair", "--unexported")
+	generate(t, dir, "pair_gen.go", "accessors", "Pair", "--unexported", "--fields", "^[A-Z]")
+	goTest(t, dir)
+}
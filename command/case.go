@@ -0,0 +1,82 @@
+package command
+
+import (
+	"strings"
+	"unicode"
+)
+
+// splitWords splits the identifier into words by underscores, hyphens, spaces and cases.
+// Acronyms are kept in a word such as "HTTP" and "Server" in "HTTPServer".
+func splitWords(s string) []string {
+	words := []string{}
+	runes := []rune(s)
+	start := 0
+	for i := 0; i <= len(runes); i++ {
+		if i == len(runes) || runes[i] == '_' || runes[i] == '-' || unicode.IsSpace(runes[i]) {
+			if start < i {
+				words = append(words, string(runes[start:i]))
+			}
+			start = i + 1
+			continue
+		}
+		if i == start {
+			continue
+		}
+
+		prev, r := runes[i-1], runes[i]
+		// "fooBar", "v2Api" or "HTTPServer"
+		lowerToUpper := (unicode.IsLower(prev) || unicode.IsDigit(prev)) && unicode.IsUpper(r)
+		acronymEnd := unicode.IsUpper(prev) && unicode.IsUpper(r) && i+1 < len(runes) && unicode.IsLower(runes[i+1])
+		if lowerToUpper || acronymEnd {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+	return words
+}
+
+func upperFirst(s string) string {
+	runes := []rune(s)
+	if len(runes) == 0 {
+		return s
+	}
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
+
+// camelCase converts the identifier into "fooBarBaz".
+func camelCase(s string) string {
+	words := splitWords(s)
+	for i, word := range words {
+		if i == 0 {
+			words[i] = strings.ToLower(word)
+		} else {
+			words[i] = upperFirst(strings.ToLower(word))
+		}
+	}
+	return strings.Join(words, "")
+}
+
+// pascalCase converts the identifier into "FooBarBaz".
+func pascalCase(s string) string {
+	words := splitWords(s)
+	for i, word := range words {
+		words[i] = upperFirst(strings.ToLower(word))
+	}
+	return strings.Join(words, "")
+}
+
+// snakeCase converts the identifier into "foo_bar_baz".
+func snakeCase(s string) string {
+	return strings.ToLower(strings.Join(splitWords(s), "_"))
+}
+
+// kebabCase converts the identifier into "foo-bar-baz".
+func kebabCase(s string) string {
+	return strings.ToLower(strings.Join(splitWords(s), "-"))
+}
+
+// upperSnakeCase converts the identifier into "FOO_BAR_BAZ".
+func upperSnakeCase(s string) string {
+	return strings.ToUpper(strings.Join(splitWords(s), "_"))
+}
@@ -0,0 +1,46 @@
+package command
+
+import "testing"
+
+func TestCase(t *testing.T) {
+	tests := []struct {
+		in         string
+		camel      string
+		pascal     string
+		snake      string
+		kebab      string
+		upperSnake string
+	}{
+		{"", "", "", "", "", ""},
+		{"foo", "foo", "Foo", "foo", "foo", "FOO"},
+		{"fooBarBaz", "fooBarBaz", "FooBarBaz", "foo_bar_baz", "foo-bar-baz", "FOO_BAR_BAZ"},
+		{"HTTPServer", "httpServer", "HttpServer", "http_server", "http-server", "HTTP_SERVER"},
+		{"XMLHttpRequest", "xmlHttpRequest", "XmlHttpRequest", "xml_http_request", "xml-http-request", "XML_HTTP_REQUEST"},
+		{"userID", "userId", "UserId", "user_id", "user-id", "USER_ID"},
+		{"ID", "id", "Id", "id", "id", "ID"},
+		{"ID2", "id2", "Id2", "id2", "id2", "ID2"},
+		{"v2Api", "v2Api", "V2Api", "v2_api", "v2-api", "V2_API"},
+		{"Status2XX", "status2Xx", "Status2Xx", "status2_xx", "status2-xx", "STATUS2_XX"},
+		{"foo_bar-baz qux", "fooBarBazQux", "FooBarBazQux", "foo_bar_baz_qux", "foo-bar-baz-qux", "FOO_BAR_BAZ_QUX"},
+		{"__foo__Bar", "fooBar", "FooBar", "foo_bar", "foo-bar", "FOO_BAR"},
+		{"StatusOK", "statusOk", "StatusOk", "status_ok", "status-ok", "STATUS_OK"},
+	}
+
+	for _, tt := range tests {
+		for _, c := range []struct {
+			name string
+			fn   func(string) string
+			want string
+		}{
+			{"camelCase", camelCase, tt.camel},
+			{"pascalCase", pascalCase, tt.pascal},
+			{"snakeCase", snakeCase, tt.snake},
+			{"kebabCase", kebabCase, tt.kebab},
+			{"upperSnakeCase", upperSnakeCase, tt.upperSnake},
+		} {
+			if got := c.fn(tt.in); got != c.want {
+				t.Errorf("%s(%q) = %q, want %q", c.name, tt.in, got, c.want)
+			}
+		}
+	}
+}
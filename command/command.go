@@ -1,18 +1,45 @@
 package command
 
-import "github.com/spf13/cobra"
+import (
+	"strings"
+
+	"github.com/spf13/cobra"
+)
 
 // New is a constructor of gogtok commands
 func New() *cobra.Command {
+	c := &config{}
 	cmd := &cobra.Command{
-		Use: "gogtok",
+		Use:               "gogtok",
+		PersistentPreRunE: c.apply,
 	}
 
-	cmd.AddCommand(newGlue())
+	cmd.AddCommand(newAccessors(c))
+	cmd.AddCommand(newComplete())
+	cmd.AddCommand(newCompletion())
+	cmd.AddCommand(newDeepCopy(c))
+	cmd.AddCommand(newEnum(c))
+	cmd.AddCommand(newEqual(c))
+	cmd.AddCommand(newFmt(c))
+	cmd.AddCommand(newGlue(c))
 	cmd.AddCommand(newImport())
-	cmd.AddCommand(newList())
+	cmd.AddCommand(newLayout(c))
+	cmd.AddCommand(newList(c))
+	cmd.AddCommand(newMock(c))
 	cmd.AddCommand(newNew())
+	cmd.AddCommand(newOptions(c))
 	cmd.AddCommand(newPackage())
+	cmd.AddCommand(newRender(c))
+	cmd.AddCommand(newRun(c))
+	cmd.AddCommand(newSQLScan(c))
+	cmd.AddCommand(newValidate(c))
+	cmd.AddCommand(newVerify(c))
+	cmd.AddCommand(newWrite())
 
 	return cmd
 }
+
+// commandPath returns the path of the command without the root such as "list fields".
+func commandPath(cmd *cobra.Command) string {
+	return strings.TrimPrefix(strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()), " ")
+}
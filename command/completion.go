@@ -0,0 +1,383 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/utisam/gogtok/query"
+)
+
+// argCompleter returns candidates of the argument following args.
+type argCompleter func(args []string, toComplete string) ([]string, error)
+
+// argCompleters are completers of arguments keyed by command paths without the root.
+var argCompleters = map[string]argCompleter{
+	"glue":          completeGlue,
+	"import":        completeImportPaths,
+	"layout":        completeLayout,
+	"list elements": completeFileDecls(valueNames),
+	"list fields":   completeFileDecls(typeNames),
+	"list params":   completeFileDecls(funcNames),
+	"list results":  completeFileDecls(funcNames),
+}
+
+// parseGoFiles parses Go files skipping files with errors.
+func parseGoFiles(filenames []string) []*ast.File {
+	res := []*ast.File{}
+	fset := token.NewFileSet()
+	for _, filename := range filenames {
+		f, err := parser.ParseFile(fset, filename, nil, 0)
+		if err == nil {
+			res = append(res, f)
+		}
+	}
+	return res
+}
+
+func parseGoFilesInDir(dir string) []*ast.File {
+	matches, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil
+	}
+	return parseGoFiles(matches)
+}
+
+// typeNames returns names of structs and interfaces.
+func typeNames(files []*ast.File) []string {
+	res := []string{}
+	for _, f := range files {
+		ast.Inspect(f, func(n ast.Node) bool {
+			typeSpec, ok := n.(*ast.TypeSpec)
+			if !ok {
+				return true
+			}
+			switch typeSpec.Type.(type) {
+			case *ast.StructType, *ast.InterfaceType:
+				res = append(res, typeSpec.Name.Name)
+			}
+			return false
+		})
+	}
+	return res
+}
+
+// structNames returns names of structs.
+func structNames(files []*ast.File) []string {
+	res := []string{}
+	for _, f := range files {
+		ast.Inspect(f, func(n ast.Node) bool {
+			typeSpec, ok := n.(*ast.TypeSpec)
+			if !ok {
+				return true
+			}
+			if _, ok := typeSpec.Type.(*ast.StructType); ok {
+				res = append(res, typeSpec.Name.Name)
+			}
+			return false
+		})
+	}
+	return res
+}
+
+// fieldNames returns names of fields of all structs.
+func fieldNames(files []*ast.File) []string {
+	res := []string{}
+	for _, f := range files {
+		ast.Inspect(f, func(n ast.Node) bool {
+			structType, ok := n.(*ast.StructType)
+			if !ok {
+				return true
+			}
+			for _, field := range structType.Fields.List {
+				for _, name := range field.Names {
+					res = append(res, name.Name)
+				}
+			}
+			return true
+		})
+	}
+	return res
+}
+
+// funcNames returns names of functions, methods and function types as "list params" accepts.
+func funcNames(files []*ast.File) []string {
+	res := []string{}
+	for _, f := range files {
+		for _, fn := range query.Funcs(f) {
+			if fn.Recv != "" {
+				res = append(res, fn.Recv+"."+fn.Name)
+			} else {
+				res = append(res, fn.Name)
+			}
+		}
+
+		ast.Inspect(f, func(n ast.Node) bool {
+			typeSpec, ok := n.(*ast.TypeSpec)
+			if !ok {
+				return true
+			}
+			switch t := typeSpec.Type.(type) {
+			case *ast.FuncType:
+				res = append(res, typeSpec.Name.Name)
+			case *ast.InterfaceType:
+				for _, method := range t.Methods.List {
+					for _, name := range method.Names {
+						res = append(res, typeSpec.Name.Name+"."+name.Name)
+					}
+				}
+			}
+			return false
+		})
+	}
+	return res
+}
+
+// valueNames returns names of variables and constants.
+func valueNames(files []*ast.File) []string {
+	res := []string{}
+	for _, f := range files {
+		for _, value := range query.Values(f) {
+			res = append(res, value.Name)
+		}
+	}
+	return res
+}
+
+// completeFileDecls completes declarations in the file given as the first argument.
+func completeFileDecls(names func([]*ast.File) []string) argCompleter {
+	return func(args []string, _ string) ([]string, error) {
+		if len(args) != 1 {
+			return nil, nil
+		}
+		return names(parseGoFiles(args[:1])), nil
+	}
+}
+
+// completeLayout completes structs in the package of the current directory.
+func completeLayout(args []string, _ string) ([]string, error) {
+	if len(args) != 0 {
+		return nil, nil
+	}
+	return structNames(parseGoFilesInDir(".")), nil
+}
+
+// completeGlue completes fields of structs in the package of the current directory.
+func completeGlue(args []string, _ string) ([]string, error) {
+	if len(args) == 0 {
+		return nil, nil
+	}
+	return fieldNames(parseGoFilesInDir(".")), nil
+}
+
+// completeImportPaths completes packages in the current module and standard packages.
+func completeImportPaths(_ []string, _ string) ([]string, error) {
+	res, err := standardPackages()
+	if err != nil {
+		return nil, err
+	}
+
+	absDir, err := filepath.Abs(".")
+	if err != nil {
+		return nil, err
+	}
+	goMod, err := query.FindGoModFile(absDir)
+	if err != nil {
+		return res, nil
+	}
+
+	dirs, err := query.ExpandPatterns([]string{filepath.Join(filepath.Dir(goMod), "...")})
+	if err != nil {
+		return nil, err
+	}
+	for _, dir := range dirs {
+		if importPath, err := query.PackagePath(dir); err == nil {
+			res = append(res, importPath)
+		}
+	}
+	return res, nil
+}
+
+// positionalArgs removes flags and their values from the arguments.
+func positionalArgs(cmd *cobra.Command, args []string) []string {
+	res := []string{}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			return append(res, args[i+1:]...)
+		}
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			res = append(res, arg)
+			continue
+		}
+		if strings.Contains(arg, "=") {
+			continue
+		}
+
+		var flag *pflag.Flag
+		if name := strings.TrimPrefix(arg, "--"); name != arg {
+			flag = cmd.Flags().Lookup(name)
+		} else if len(arg) == 2 {
+			flag = cmd.Flags().ShorthandLookup(arg[1:])
+		}
+		if flag != nil && flag.NoOptDefVal == "" {
+			// Skip the value of the flag
+			i++
+		}
+	}
+	return res
+}
+
+// complete returns candidates of the last argument.
+func complete(root *cobra.Command, args []string) ([]string, error) {
+	if len(args) == 0 {
+		return nil, errors.New("no argument to complete")
+	}
+	toComplete := args[len(args)-1]
+	args = args[:len(args)-1]
+
+	cmd, rest, err := root.Find(args)
+	if err != nil {
+		return nil, nil
+	}
+	positional := positionalArgs(cmd, rest)
+
+	candidates := []string{}
+	switch {
+	case strings.HasPrefix(toComplete, "-"):
+		cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+			if flag.Hidden {
+				return
+			}
+			candidates = append(candidates, "--"+flag.Name)
+			if flag.Shorthand != "" {
+				candidates = append(candidates, "-"+flag.Shorthand)
+			}
+		})
+	case cmd.HasAvailableSubCommands() && len(positional) == 0:
+		for _, sub := range cmd.Commands() {
+			if sub.IsAvailableCommand() {
+				candidates = append(candidates, sub.Name())
+			}
+		}
+	default:
+		if completer, ok := argCompleters[commandPath(cmd)]; ok {
+			candidates, err = completer(positional, toComplete)
+			if err != nil {
+				return nil, err
+			}
+		} else if len(positional) == 0 {
+			candidates = cmd.ValidArgs
+		}
+	}
+
+	seen := map[string]bool{}
+	res := []string{}
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, toComplete) && !seen[candidate] {
+			seen[candidate] = true
+			res = append(res, candidate)
+		}
+	}
+	sort.Strings(res)
+	return res, nil
+}
+
+const bashCompletion = `_gogtok() {
+	local cur="${COMP_WORDS[COMP_CWORD]}"
+	local IFS=$'\n'
+	COMPREPLY=($("${COMP_WORDS[0]}" __complete "${COMP_WORDS[@]:1:COMP_CWORD-1}" "$cur" 2>/dev/null))
+}
+complete -o default -F _gogtok gogtok
+`
+
+const zshCompletion = `#compdef gogtok
+
+_gogtok() {
+	local -a candidates
+	candidates=("${(@f)$("${words[1]}" __complete "${(@)words[2,CURRENT-1]}" "${words[CURRENT]}" 2>/dev/null)}")
+	candidates=(${candidates:#})
+	if (( ${#candidates} )); then
+		compadd -a candidates
+	else
+		_files
+	fi
+}
+
+compdef _gogtok gogtok
+`
+
+const fishCompletion = `function __gogtok_complete
+	set -l args (commandline -opc)
+	set -l candidates ($args[1] __complete $args[2..-1] (commandline -ct) 2>/dev/null)
+	if test (count $candidates) -gt 0
+		printf '%s\n' $candidates
+	else
+		__fish_complete_path (commandline -ct)
+	end
+end
+
+complete -c gogtok -f -a '(__gogtok_complete)'
+`
+
+func newCompletion() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "completion bash|zsh|fish",
+		Short: "Generate shell completion script",
+		Long: `Generate shell completion script.
+
+Arguments are completed dynamically with Go identifiers such as struct names for "list fields FILE",
+field names for "glue" and import paths in the module and GOROOT for "import".`,
+		Example: `  source <(gogtok completion bash)
+  gogtok completion zsh > "${fpath[1]}/_gogtok"
+  gogtok completion fish > ~/.config/fish/completions/gogtok.fish`,
+		Args:      cobra.ExactArgs(1),
+		ValidArgs: []string{"bash", "zsh", "fish"},
+		RunE: func(_ *cobra.Command, args []string) error {
+			script := ""
+			switch args[0] {
+			case "bash":
+				script = bashCompletion
+			case "zsh":
+				script = zshCompletion
+			case "fish":
+				script = fishCompletion
+			default:
+				return fmt.Errorf("unsupported shell: %s", args[0])
+			}
+
+			_, err := io.WriteString(os.Stdout, script)
+			return err
+		},
+	}
+	return cmd
+}
+
+func newComplete() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                "__complete [args...] TO_COMPLETE",
+		Short:              "Print candidates of the last argument for shell completion",
+		Hidden:             true,
+		DisableFlagParsing: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			candidates, err := complete(cmd.Root(), args)
+			if err != nil {
+				return err
+			}
+			for _, candidate := range candidates {
+				fmt.Println(candidate)
+			}
+			return nil
+		},
+	}
+	return cmd
+}
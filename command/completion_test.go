@@ -0,0 +1,96 @@
+package command
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestComplete(t *testing.T) {
+	dir := newTestModule(t, map[string]string{
+		"a.go": `package test
+
+type Server struct {
+	Name string
+	Port int
+}
+
+type Handler interface {
+	Serve(req string) error
+}
+
+type Option func(*Server)
+
+func (s *Server) Start() {}
+
+func NewServer(opts ...Option) *Server { return nil }
+
+const DefaultPort = 80
+
+var DefaultServer = Server{Name: "default"}
+`,
+		"sub/b.go":  "package sub\n",
+		"broken.go": "package test\n\nfunc {\n",
+	})
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	tests := []struct {
+		args []string
+		want []string
+	}{
+		{[]string{"li"}, []string{"list"}},
+		{[]string{"list", "e"}, []string{"elements", "embeds"}},
+		{[]string{"completion", ""}, []string{"bash", "fish", "zsh"}},
+		{[]string{"layout", "--su"}, []string{"--summary"}},
+		{[]string{"layout", ""}, []string{"Server"}},
+		{[]string{"layout", "--goarch", "386", "S"}, []string{"Server"}},
+		{[]string{"layout", "Server", ""}, []string{}},
+		{[]string{"list", "fields", "a.go", ""}, []string{"Handler", "Server"}},
+		{[]string{"list", "params", "a.go", ""}, []string{"Handler.Serve", "NewServer", "Option", "Server.Start"}},
+		{[]string{"list", "elements", "-0", "a.go", "Def"}, []string{"DefaultPort", "DefaultServer"}},
+		{[]string{"glue", "Server", "P"}, []string{"Port"}},
+		{[]string{"import", "example.com/"}, []string{"example.com/test", "example.com/test/sub"}},
+		{[]string{"import", "encoding/bas"}, []string{"encoding/base32", "encoding/base64"}},
+		{[]string{"unknown", ""}, []string{}},
+	}
+	for _, tt := range tests {
+		got, err := complete(New(), tt.args)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+			t.Errorf("complete(%q) = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+
+	out, err := gogtokStdout(t, "__complete", "list", "fields", "a.go", "S")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != "Server\n" {
+		t.Errorf("__complete = %q", out)
+	}
+}
+
+func TestCompletion(t *testing.T) {
+	for _, shell := range []string{"bash", "zsh", "fish"} {
+		out, err := gogtokStdout(t, "completion", shell)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(out, "__complete") {
+			t.Errorf("completion %s does not call __complete:\n%s", shell, out)
+		}
+	}
+
+	if err := runGogtok("completion", "powershell"); err == nil {
+		t.Error("unsupported shells must be rejected")
+	}
+}
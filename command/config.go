@@ -0,0 +1,155 @@
+package command
+
+import (
+	"fmt"
+	"go/build"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/utisam/gogtok/query"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// configFileName is the name of the project configuration file.
+const configFileName = ".gogtok.yaml"
+
+// config is the project configuration.
+type config struct {
+	// Flags are defaults of flags keyed by command paths without "gogtok" (e.g. "list fields").
+	Flags map[string]map[string]interface{} `yaml:"flags"`
+	// Templates are named line templates of glue.
+	Templates map[string]string `yaml:"templates"`
+	// Converters are functions converting fields keyed by their types for "glue --typed".
+	Converters map[string]string `yaml:"converters"`
+	// Imports are import paths keyed by package names to resolve missing imports.
+	Imports map[string]string `yaml:"imports"`
+	// BuildTags are build tags to select files of packages.
+	BuildTags []string `yaml:"build_tags"`
+}
+
+// findConfigFile finds the configuration file in the directory or its parents.
+func findConfigFile(absDir string) (string, error) {
+	for {
+		filePath := filepath.Join(absDir, configFileName)
+
+		info, err := os.Stat(filePath)
+		if err == nil && !info.IsDir() {
+			return filePath, nil
+		} else if err != nil && !os.IsNotExist(err) {
+			return "", err
+		}
+
+		parent := filepath.Dir(absDir)
+		if parent == absDir {
+			return "", os.ErrNotExist
+		}
+		absDir = parent
+	}
+}
+
+// loadConfig loads the configuration file found from the working directory and appends it to the manifest.
+// An empty configuration is returned if it is not found.
+func loadConfig() (*config, error) {
+	absDir, err := filepath.Abs(".")
+	if err != nil {
+		return nil, err
+	}
+
+	filename, err := findConfigFile(absDir)
+	if os.IsNotExist(err) {
+		return &config{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err := recordInputs(filename); err != nil {
+		return nil, err
+	}
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &config{}
+	if err := yaml.UnmarshalStrict(b, c); err != nil {
+		return nil, fmt.Errorf("%s: %v", filename, err)
+	}
+	return c, nil
+}
+
+// applyFlags sets flags of the command which are not specified in the command line.
+// They are treated as if they are specified.
+func (c *config) applyFlags(cmd *cobra.Command) error {
+	path := commandPath(cmd)
+	for name, value := range c.Flags[path] {
+		flag := cmd.Flags().Lookup(name)
+		if flag == nil {
+			return fmt.Errorf("%s: unknown flag of %q: %s", configFileName, path, name)
+		}
+		if flag.Changed {
+			continue
+		}
+
+		values := []interface{}{value}
+		if list, ok := value.([]interface{}); ok {
+			values = list
+		}
+		for _, v := range values {
+			if err := cmd.Flags().Set(name, fmt.Sprint(v)); err != nil {
+				return fmt.Errorf("%s: invalid value of %q: %s: %v", configFileName, path, name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// imports returns import aliases as imports.
+func (c *config) imports() []*query.Import {
+	res := make([]*query.Import, 0, len(c.Imports))
+	for name, importPath := range c.Imports {
+		imp := &query.Import{Path: importPath}
+		if name != assumedPackageName(importPath) {
+			imp.Name = name
+		}
+		res = append(res, imp)
+	}
+	return res
+}
+
+// queryConfig returns options to load packages with the build tags.
+func (c *config) queryConfig(tests bool) *query.LoadConfig {
+	return &query.LoadConfig{
+		Tests:     tests,
+		BuildTags: c.BuildTags,
+	}
+}
+
+// buildContext returns the build context with the build tags to select files.
+func (c *config) buildContext() *build.Context {
+	return c.queryConfig(false).BuildContext()
+}
+
+// skipConfigCommands are commands for shell completion which do not use the configuration.
+// A malformed configuration file must not break completion.
+var skipConfigCommands = map[string]bool{
+	"__complete": true,
+	"completion": true,
+}
+
+// apply loads the configuration into c and applies it to the command before running it.
+// Commands are constructed with c before it is loaded and refer it while running.
+func (c *config) apply(cmd *cobra.Command, _ []string) error {
+	if skipConfigCommands[commandPath(cmd)] {
+		return nil
+	}
+
+	loaded, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	*c = *loaded
+	return c.applyFlags(cmd)
+}
@@ -0,0 +1,49 @@
+package command
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConfigApply(t *testing.T) {
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	write := func(content string) {
+		if err := ioutil.WriteFile(filepath.Join(dir, configFileName), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("flags:\n  package path:\n    unknown: 1\n")
+	if err := runGogtok("package", "path", "."); err == nil || !strings.Contains(err.Error(), `unknown flag of "package path": unknown`) {
+		t.Errorf("unknown flags must be errors: %v", err)
+	}
+
+	write("flags: [malformed\n")
+	if err := runGogtok("package", "path", "."); err == nil || !strings.Contains(err.Error(), configFileName) {
+		t.Errorf("malformed configuration must be an error: %v", err)
+	}
+
+	// Completion works without loading the configuration
+	stdout := os.Stdout
+	os.Stdout, _ = os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	defer func() {
+		os.Stdout = stdout
+	}()
+	for _, args := range [][]string{{"completion", "bash"}, {"__complete", "list", ""}} {
+		if err := runGogtok(args...); err != nil {
+			t.Errorf("gogtok %s: %v", strings.Join(args, " "), err)
+		}
+	}
+}
@@ -0,0 +1,354 @@
+package command
+
+import (
+	"fmt"
+	"go/types"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/utisam/gogtok/query"
+)
+
+// deepCopyMethod is how values of a named type are copied.
+type deepCopyMethod int
+
+const (
+	// deepCopyNone copies fields of the underlying type.
+	deepCopyNone deepCopyMethod = iota
+	// deepCopyInto calls DeepCopyInto(*T).
+	deepCopyInto
+	// deepCopyValue calls DeepCopy() T.
+	deepCopyValue
+	// deepCopyPointer calls DeepCopy() *T.
+	deepCopyPointer
+)
+
+// deepCopyGenerator generates DeepCopyInto and DeepCopy of the type
+// and named types in the same package reached from it.
+type deepCopyGenerator struct {
+	pkg  *query.Package
+	opts *generatorOptions
+	// typeName is the name of the type specified in the command line.
+	typeName string
+	imports  *generatorImports
+	queue    []*types.Named
+	queued   map[*types.Named]bool
+	numVars  int
+}
+
+// existingMethod returns the method of the named type unless it is declared in files to be replaced.
+func (g *deepCopyGenerator) existingMethod(named *types.Named, name string) *types.Signature {
+	obj, _, _ := types.LookupFieldOrMethod(types.NewPointer(named), true, named.Obj().Pkg(), name)
+	fn, ok := obj.(*types.Func)
+	if !ok || g.opts.replaces(g.pkg, fn, g.typeName) {
+		return nil
+	}
+	return fn.Type().(*types.Signature)
+}
+
+// hasMethods reports whether the named type already has DeepCopyInto or DeepCopy.
+func (g *deepCopyGenerator) hasMethods(named *types.Named) bool {
+	return g.existingMethod(named, "DeepCopyInto") != nil || g.existingMethod(named, "DeepCopy") != nil
+}
+
+// generated reports whether methods of the named type are generated.
+func (g *deepCopyGenerator) generated(named *types.Named) bool {
+	if named.Obj().Pkg() != g.pkg.Types || g.hasMethods(named) {
+		return false
+	}
+	switch named.Underlying().(type) {
+	case *types.Struct, *types.Slice, *types.Map, *types.Array:
+		return g.needsCopy(named.Underlying())
+	}
+	return false
+}
+
+func (g *deepCopyGenerator) method(named *types.Named) deepCopyMethod {
+	if sig := g.existingMethod(named, "DeepCopyInto"); sig != nil {
+		if sig.Params().Len() == 1 && sig.Results().Len() == 0 && types.Identical(sig.Params().At(0).Type(), types.NewPointer(named)) {
+			return deepCopyInto
+		}
+	}
+	if sig := g.existingMethod(named, "DeepCopy"); sig != nil {
+		if sig.Params().Len() == 0 && sig.Results().Len() == 1 {
+			switch res := sig.Results().At(0).Type(); {
+			case types.Identical(res, named):
+				return deepCopyValue
+			case types.Identical(res, types.NewPointer(named)):
+				return deepCopyPointer
+			}
+		}
+	}
+	if g.generated(named) {
+		return deepCopyInto
+	}
+	return deepCopyNone
+}
+
+// hasUnexportedFields reports whether the struct of another package has fields which cannot be copied.
+func (g *deepCopyGenerator) hasUnexportedFields(named *types.Named) bool {
+	st, ok := named.Underlying().(*types.Struct)
+	if !ok || named.Obj().Pkg() == g.pkg.Types {
+		return false
+	}
+	for i := 0; i < st.NumFields(); i++ {
+		if !st.Field(i).Exported() {
+			return true
+		}
+	}
+	return false
+}
+
+// needsCopy reports whether assignment of the type shares memory.
+// Interfaces, functions and channels are shared.
+func (g *deepCopyGenerator) needsCopy(t types.Type) bool {
+	if named, ok := t.(*types.Named); ok {
+		if g.method(named) != deepCopyNone {
+			return true
+		}
+		if g.hasUnexportedFields(named) {
+			return false
+		}
+	}
+
+	switch u := t.Underlying().(type) {
+	case *types.Pointer, *types.Slice, *types.Map:
+		return true
+	case *types.Array:
+		return g.needsCopy(u.Elem())
+	case *types.Struct:
+		for i := 0; i < u.NumFields(); i++ {
+			if g.needsCopy(u.Field(i).Type()) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (g *deepCopyGenerator) enqueue(named *types.Named) {
+	if g.generated(named) && !g.queued[named] {
+		g.queued[named] = true
+		g.queue = append(g.queue, named)
+	}
+}
+
+func (g *deepCopyGenerator) newVar(prefix string) string {
+	g.numVars++
+	return fmt.Sprint(prefix, g.numVars)
+}
+
+func (g *deepCopyGenerator) typeString(t types.Type) string {
+	return types.TypeString(t, g.imports.qualifier)
+}
+
+// operand parenthesizes the expression to be used with selectors and indexes.
+func operand(expr string) string {
+	if strings.HasPrefix(expr, "*") {
+		return "(" + expr + ")"
+	}
+	return expr
+}
+
+// assign writes statements to assign a deep copy of src to dst.
+// dst must be addressable and either the zero value or a shallow copy of src.
+func (g *deepCopyGenerator) assign(b *strings.Builder, dst, src string, t types.Type) {
+	if !g.needsCopy(t) {
+		fmt.Fprintf(b, "%s = %s\n", dst, src)
+		return
+	}
+
+	if named, ok := t.(*types.Named); ok {
+		g.enqueue(named)
+		switch g.method(named) {
+		case deepCopyInto:
+			fmt.Fprintf(b, "%s.DeepCopyInto(&%s)\n", operand(src), dst)
+			return
+		case deepCopyValue:
+			fmt.Fprintf(b, "%s = %s.DeepCopy()\n", dst, operand(src))
+			return
+		case deepCopyPointer:
+			fmt.Fprintf(b, "%s = *%s.DeepCopy()\n", dst, operand(src))
+			return
+		}
+	}
+
+	switch u := t.Underlying().(type) {
+	case *types.Pointer:
+		fmt.Fprintf(b, "if %s != nil {\n", src)
+		elem, _ := u.Elem().(*types.Named)
+		if elem != nil {
+			g.enqueue(elem)
+		}
+		switch {
+		case elem != nil && g.method(elem) == deepCopyPointer:
+			fmt.Fprintf(b, "%s = %s.DeepCopy()\n", dst, src)
+		case elem != nil && g.method(elem) == deepCopyInto:
+			p := g.newVar("p")
+			fmt.Fprintf(b, "%s := new(%s)\n", p, g.typeString(u.Elem()))
+			fmt.Fprintf(b, "%s.DeepCopyInto(%s)\n", src, p)
+			fmt.Fprintf(b, "%s = %s\n", dst, p)
+		default:
+			p := g.newVar("p")
+			fmt.Fprintf(b, "%s := new(%s)\n", p, g.typeString(u.Elem()))
+			g.assign(b, "*"+p, "*"+operand(src), u.Elem())
+			fmt.Fprintf(b, "%s = %s\n", dst, p)
+		}
+		b.WriteString("}\n")
+
+	case *types.Slice:
+		s := g.newVar("s")
+		fmt.Fprintf(b, "if %s != nil {\n", src)
+		fmt.Fprintf(b, "%s := make(%s, len(%s))\n", s, g.typeString(t), src)
+		if g.needsCopy(u.Elem()) {
+			i := g.newVar("i")
+			fmt.Fprintf(b, "for %s := range %s {\n", i, src)
+			g.assign(b, s+"["+i+"]", operand(src)+"["+i+"]", u.Elem())
+			b.WriteString("}\n")
+		} else {
+			fmt.Fprintf(b, "copy(%s, %s)\n", s, src)
+		}
+		fmt.Fprintf(b, "%s = %s\n", dst, s)
+		b.WriteString("}\n")
+
+	case *types.Map:
+		m, k, v := g.newVar("m"), g.newVar("k"), g.newVar("v")
+		fmt.Fprintf(b, "if %s != nil {\n", src)
+		fmt.Fprintf(b, "%s := make(%s, len(%s))\n", m, g.typeString(t), src)
+		fmt.Fprintf(b, "for %s, %s := range %s {\n", k, v, src)
+		if g.needsCopy(u.Elem()) {
+			c := g.newVar("c")
+			fmt.Fprintf(b, "var %s %s\n", c, g.typeString(u.Elem()))
+			g.assign(b, c, v, u.Elem())
+			fmt.Fprintf(b, "%s[%s] = %s\n", m, k, c)
+		} else {
+			fmt.Fprintf(b, "%s[%s] = %s\n", m, k, v)
+		}
+		b.WriteString("}\n")
+		fmt.Fprintf(b, "%s = %s\n", dst, m)
+		b.WriteString("}\n")
+
+	case *types.Array:
+		i := g.newVar("i")
+		fmt.Fprintf(b, "for %s := range %s {\n", i, src)
+		g.assign(b, operand(dst)+"["+i+"]", operand(src)+"["+i+"]", u.Elem())
+		b.WriteString("}\n")
+
+	case *types.Struct:
+		fmt.Fprintf(b, "%s = %s\n", dst, src)
+		g.assignFields(b, dst, src, u)
+
+	default:
+		fmt.Fprintf(b, "%s = %s\n", dst, src)
+	}
+}
+
+// assignFields writes statements to copy fields which need deep copies.
+func (g *deepCopyGenerator) assignFields(b *strings.Builder, dst, src string, st *types.Struct) {
+	for i := 0; i < st.NumFields(); i++ {
+		f := st.Field(i)
+		if f.Name() != "_" && g.needsCopy(f.Type()) {
+			g.assign(b, operand(dst)+"."+f.Name(), operand(src)+"."+f.Name(), f.Type())
+		}
+	}
+}
+
+type deepCopyTemplateData struct {
+	Type string
+	Body string
+}
+
+const deepCopyTemplate = `
+// DeepCopyInto copies x into out deeply.
+func (x *{{.Type}}) DeepCopyInto(out *{{.Type}}) {
+	*out = *x
+{{.Body -}}
+}
+
+// DeepCopy returns a deep copy of x.
+func (x *{{.Type}}) DeepCopy() *{{.Type}} {
+	if x == nil {
+		return nil
+	}
+	out := new({{.Type}})
+	x.DeepCopyInto(out)
+	return out
+}
+`
+
+func newDeepCopy(c *config) *cobra.Command {
+	opts := &generatorOptions{config: c}
+
+	cmd := &cobra.Command{
+		Use:   "deepcopy TYPE [package]",
+		Short: "Generate DeepCopyInto and DeepCopy methods of the type",
+		Long: `Generate DeepCopyInto and DeepCopy methods of the type.
+
+Pointers, slices, maps and structs are copied recursively.
+Existing DeepCopyInto(*T) or DeepCopy() methods of types are called, even if they are in other packages.
+Named types in the same package reached from the type get the methods too.
+Interfaces, functions, channels and structs of other packages having unexported fields are copied shallowly.`,
+		Example: `  gogtok deepcopy Config -o config_deepcopy.go`,
+		Args:    cobra.RangeArgs(1, 2),
+		RunE: func(_ *cobra.Command, args []string) error {
+			typeName := args[0]
+			dir := "."
+			if len(args) > 1 {
+				dir = args[1]
+			}
+
+			pkg, obj, err := opts.lookupType(dir, typeName)
+			if err != nil {
+				return err
+			}
+			named, ok := obj.Type().(*types.Named)
+			if !ok {
+				return fmt.Errorf("not a named type: %s", typeName)
+			}
+
+			g := &deepCopyGenerator{
+				pkg:      pkg,
+				opts:     opts,
+				typeName: typeName,
+				imports:  newGeneratorImports(pkg.Types.Path()),
+				queued:   map[*types.Named]bool{},
+			}
+			if g.hasMethods(named) {
+				return fmt.Errorf("%s already has DeepCopyInto or DeepCopy", typeName)
+			}
+			if !g.generated(named) {
+				return fmt.Errorf("%s does not need deep copies", typeName)
+			}
+			g.enqueue(named)
+
+			b := &strings.Builder{}
+			for len(g.queue) > 0 {
+				t := g.queue[0]
+				g.queue = g.queue[1:]
+
+				g.numVars = 0
+				body := &strings.Builder{}
+				if st, ok := t.Underlying().(*types.Struct); ok {
+					g.assignFields(body, "out", "x", st)
+				} else {
+					g.assign(body, "*out", "*x", t.Underlying())
+				}
+
+				src, err := executeTemplate(deepCopyTemplate, &deepCopyTemplateData{
+					Type: t.Obj().Name(),
+					Body: body.String(),
+				})
+				if err != nil {
+					return err
+				}
+				b.Write(src)
+			}
+
+			return opts.write(dir, pkg.Types.Name(), []byte(b.String()), g.imports.imports...)
+		},
+	}
+
+	opts.addFlags(cmd)
+
+	return cmd
+}
@@ -0,0 +1,82 @@
+package command
+
+import "testing"
+
+func TestDeepCopy(t *testing.T) {
+	dir := newTestModule(t, map[string]string{
+		"node.go": `package test
+
+import "time"
+
+type Node struct {
+	Name     string
+	Children []*Node
+	Attrs    map[string][]int
+	Matrix   [2][]byte
+	Meta     Meta
+	Created  time.Time
+	Parent   *Node
+	Func     func()
+}
+
+type Meta struct {
+	Labels map[string]string
+}
+
+type List []*Meta
+`,
+		"node_test.go": `package test
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDeepCopy(t *testing.T) {
+	n := &Node{
+		Name:     "root",
+		Children: []*Node{{Name: "child"}, nil},
+		Attrs:    map[string][]int{"a": {1}, "nil": nil},
+		Matrix:   [2][]byte{[]byte("x")},
+		Meta:     Meta{Labels: map[string]string{"k": "v"}},
+	}
+	c := n.DeepCopy()
+	if !reflect.DeepEqual(n, c) {
+		t.Fatalf("DeepCopy() = %+v", c)
+	}
+
+	c.Children[0].Name = "changed"
+	c.Attrs["a"][0] = 2
+	c.Matrix[0][0] = 'y'
+	c.Meta.Labels["k"] = "changed"
+	if n.Children[0].Name != "child" || n.Attrs["a"][0] != 1 || n.Matrix[0][0] != 'x' || n.Meta.Labels["k"] != "v" {
+		t.Errorf("the copy shares memory with the original: %+v", n)
+	}
+	if c.Attrs["nil"] != nil || c.Children[1] != nil {
+		t.Error("nil must be copied as nil")
+	}
+
+	var nilNode *Node
+	if nilNode.DeepCopy() != nil {
+		t.Error("DeepCopy() of nil must be nil")
+	}
+}
+
+func TestDeepCopyList(t *testing.T) {
+	l := List{{Labels: map[string]string{"k": "v"}}}
+	c := l.DeepCopy()
+	(*c)[0].Labels["k"] = "changed"
+	if l[0].Labels["k"] != "v" {
+		t.Error("the copy shares memory with the original")
+	}
+}
+`,
+	})
+
+	// Previous outputs written through stdout must be replaced
+	for i := 0; i < 2; i++ {
+		generateStdout(t, dir, "node_gen.go", "deepcopy", "Node")
+	}
+	generate(t, dir, "list_gen.go", "deepcopy", "List")
+	goTest(t, dir)
+}
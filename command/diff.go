@@ -0,0 +1,231 @@
+package command
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// diffOp is an operation of the line diff. Kind is ' ', '-' or '+'.
+type diffOp struct {
+	kind byte
+	line string
+}
+
+// splitLines splits the text into lines with line terminators.
+// The last line lacks "\n" if the text does not end with a newline,
+// so it differs from the same line followed by a newline.
+func splitLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// lineDiffer computes the shortest edit script with the linear space variant of Myers' algorithm.
+type lineDiffer struct {
+	a, b []string
+	ops  []diffOp
+}
+
+// diffLines computes the shortest edit script to transform a into b.
+func diffLines(a, b []string) []diffOp {
+	d := &lineDiffer{a: a, b: b, ops: make([]diffOp, 0, len(a)+len(b))}
+	d.diff(0, len(a), 0, len(b))
+	return d.ops
+}
+
+// diff appends operations to transform a[aLo:aHi] into b[bLo:bHi].
+func (d *lineDiffer) diff(aLo, aHi, bLo, bHi int) {
+	for aLo < aHi && bLo < bHi && d.a[aLo] == d.b[bLo] {
+		d.ops = append(d.ops, diffOp{' ', d.a[aLo]})
+		aLo++
+		bLo++
+	}
+	suffix := 0
+	for aLo < aHi-suffix && bLo < bHi-suffix && d.a[aHi-suffix-1] == d.b[bHi-suffix-1] {
+		suffix++
+	}
+	aHi -= suffix
+	bHi -= suffix
+
+	switch {
+	case aLo == aHi:
+		for _, line := range d.b[bLo:bHi] {
+			d.ops = append(d.ops, diffOp{'+', line})
+		}
+	case bLo == bHi:
+		for _, line := range d.a[aLo:aHi] {
+			d.ops = append(d.ops, diffOp{'-', line})
+		}
+	default:
+		x, y := d.middleSnake(aLo, aHi, bLo, bHi)
+		d.diff(aLo, x, bLo, y)
+		d.diff(x, aHi, y, bHi)
+	}
+
+	for _, line := range d.a[aHi : aHi+suffix] {
+		d.ops = append(d.ops, diffOp{' ', line})
+	}
+}
+
+// middleSnake searches forward and backward paths at the same time
+// and returns the point where they overlap to split the problem in two.
+// Both ranges must be non-empty and have neither common prefix nor common suffix.
+func (d *lineDiffer) middleSnake(aLo, aHi, bLo, bHi int) (int, int) {
+	a, b := d.a[aLo:aHi], d.b[bLo:bHi]
+	n, m := len(a), len(b)
+	maxD := (n + m + 1) / 2
+	offset := maxD
+	// vf and vb are the furthest x on each diagonal from the start and from the end.
+	vf := make([]int, 2*maxD+2)
+	vb := make([]int, 2*maxD+2)
+	for i := range vf {
+		vf[i], vb[i] = -1, -1
+	}
+	vf[offset+1], vb[offset+1] = 0, 0
+
+	delta := n - m
+	odd := delta%2 != 0
+	// Diagonals running off the grid are skipped.
+	fStart, fEnd, bStart, bEnd := 0, 0, 0, 0
+	for dist := 0; dist < maxD; dist++ {
+		for k := -dist + fStart; k <= dist-fEnd; k += 2 {
+			i := offset + k
+			x := 0
+			if k == -dist || (k != dist && vf[i-1] < vf[i+1]) {
+				x = vf[i+1]
+			} else {
+				x = vf[i-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			vf[i] = x
+			switch {
+			case x > n:
+				fEnd += 2
+			case y > m:
+				fStart += 2
+			case odd:
+				if j := offset + delta - k; j >= 0 && j < len(vb) && vb[j] != -1 && x >= n-vb[j] {
+					return aLo + x, bLo + y
+				}
+			}
+		}
+
+		for k := -dist + bStart; k <= dist-bEnd; k += 2 {
+			i := offset + k
+			x := 0
+			if k == -dist || (k != dist && vb[i-1] < vb[i+1]) {
+				x = vb[i+1]
+			} else {
+				x = vb[i-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[n-x-1] == b[m-y-1] {
+				x++
+				y++
+			}
+			vb[i] = x
+			switch {
+			case x > n:
+				bEnd += 2
+			case y > m:
+				bStart += 2
+			case !odd:
+				if j := offset + delta - k; j >= 0 && j < len(vf) && vf[j] != -1 && vf[j] >= n-x {
+					fx := vf[j]
+					return aLo + fx, bLo + fx - (j - offset)
+				}
+			}
+		}
+	}
+
+	// The paths never overlap if there are no common lines.
+	return aHi, bLo
+}
+
+// hunkRange formats the range of the hunk header in the unified format.
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+// writeUnifiedDiff writes the difference in the unified format with context lines.
+func writeUnifiedDiff(w io.Writer, aName, bName, a, b string, context int) error {
+	ops := diffLines(splitLines(a), splitLines(b))
+
+	changes := []int{}
+	for i, op := range ops {
+		if op.kind != ' ' {
+			changes = append(changes, i)
+		}
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+
+	if _, err := fmt.Fprintf(w, "--- %s\n+++ %s\n", aName, bName); err != nil {
+		return err
+	}
+
+	for i := 0; i < len(changes); {
+		// Merge changes whose context lines overlap
+		j := i
+		for j+1 < len(changes) && changes[j+1]-changes[j] <= 2*context {
+			j++
+		}
+
+		start, end := changes[i]-context, changes[j]+context+1
+		if start < 0 {
+			start = 0
+		}
+		if end > len(ops) {
+			end = len(ops)
+		}
+
+		aStart, bStart := 0, 0
+		for _, op := range ops[:start] {
+			if op.kind != '+' {
+				aStart++
+			}
+			if op.kind != '-' {
+				bStart++
+			}
+		}
+		aCount, bCount := 0, 0
+		for _, op := range ops[start:end] {
+			if op.kind != '+' {
+				aCount++
+			}
+			if op.kind != '-' {
+				bCount++
+			}
+		}
+
+		if _, err := fmt.Fprintf(w, "@@ -%s +%s @@\n", hunkRange(aStart, aCount), hunkRange(bStart, bCount)); err != nil {
+			return err
+		}
+		for _, op := range ops[start:end] {
+			line := op.line
+			if !strings.HasSuffix(line, "\n") {
+				line += "\n\\ No newline at end of file\n"
+			}
+			if _, err := fmt.Fprintf(w, "%c%s", op.kind, line); err != nil {
+				return err
+			}
+		}
+
+		i = j + 1
+	}
+	return nil
+}
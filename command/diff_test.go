@@ -0,0 +1,148 @@
+package command
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestWriteUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want string
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name: "equal",
+			a:    "a\nb\n",
+			b:    "a\nb\n",
+		},
+		{
+			name: "insert into empty",
+			b:    "a\nb\n",
+			want: "--- a\n+++ b\n@@ -0,0 +1,2 @@\n+a\n+b\n",
+		},
+		{
+			name: "insert only",
+			a:    "a\nb\nc\nd\ne\nf\n",
+			b:    "a\nb\nc\nx\nd\ne\nf\n",
+			want: "--- a\n+++ b\n@@ -1,6 +1,7 @@\n a\n b\n c\n+x\n d\n e\n f\n",
+		},
+		{
+			name: "delete only",
+			a:    "1\n2\n3\n4\n5\n6\n7\n8\n9\n",
+			b:    "1\n2\n3\n4\n6\n7\n8\n9\n",
+			want: "--- a\n+++ b\n@@ -2,7 +2,6 @@\n 2\n 3\n 4\n-5\n 6\n 7\n 8\n",
+		},
+		{
+			name: "delete all",
+			a:    "a\n",
+			want: "--- a\n+++ b\n@@ -1 +0,0 @@\n-a\n",
+		},
+		{
+			name: "separate hunks",
+			a:    "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n",
+			b:    "0\n2\n3\n4\n5\n6\n7\n8\n9\n11\n",
+			want: "--- a\n+++ b\n@@ -1,4 +1,4 @@\n-1\n+0\n 2\n 3\n 4\n@@ -7,4 +7,4 @@\n 7\n 8\n 9\n-10\n+11\n",
+		},
+		{
+			name: "missing trailing newline",
+			a:    "a\nb",
+			b:    "a\nb\n",
+			want: "--- a\n+++ b\n@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+b\n",
+		},
+		{
+			name: "added trailing newline",
+			a:    "a\n",
+			b:    "a\nb",
+			want: "--- a\n+++ b\n@@ -1 +1,2 @@\n a\n+b\n\\ No newline at end of file\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &strings.Builder{}
+			if err := writeUnifiedDiff(b, "a", "b", tt.a, tt.b, 3); err != nil {
+				t.Fatal(err)
+			}
+			if got := b.String(); got != tt.want {
+				t.Errorf("writeUnifiedDiff() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+// lcsLength computes the length of the longest common subsequence with dynamic programming.
+func lcsLength(a, b []string) int {
+	dp := make([][]int, len(a)+1)
+	for i := range dp {
+		dp[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			switch {
+			case a[i] == b[j]:
+				dp[i][j] = dp[i+1][j+1] + 1
+			case dp[i+1][j] > dp[i][j+1]:
+				dp[i][j] = dp[i+1][j]
+			default:
+				dp[i][j] = dp[i][j+1]
+			}
+		}
+	}
+	return dp[0][0]
+}
+
+func TestDiffLinesShortest(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	randomLines := func() []string {
+		lines := make([]string, r.Intn(20))
+		for i := range lines {
+			lines[i] = string(rune('a' + r.Intn(4)))
+		}
+		return lines
+	}
+
+	for i := 0; i < 1000; i++ {
+		a, b := randomLines(), randomLines()
+		ops := diffLines(a, b)
+
+		gotA, gotB, common := []string{}, []string{}, 0
+		for _, op := range ops {
+			if op.kind != '+' {
+				gotA = append(gotA, op.line)
+			}
+			if op.kind != '-' {
+				gotB = append(gotB, op.line)
+			}
+			if op.kind == ' ' {
+				common++
+			}
+		}
+		if strings.Join(gotA, "") != strings.Join(a, "") || strings.Join(gotB, "") != strings.Join(b, "") {
+			t.Fatalf("diffLines(%q, %q) = %v does not transform a into b", a, b, ops)
+		}
+		if want := lcsLength(a, b); common != want {
+			t.Fatalf("diffLines(%q, %q) keeps %d lines, want %d", a, b, common, want)
+		}
+	}
+}
+
+func TestDiffLinesLarge(t *testing.T) {
+	// Every 10th line of a large generated file is rewritten
+	a, b := make([]string, 20000), make([]string, 20000)
+	for i := range a {
+		a[i] = fmt.Sprintf("line %d\n", i)
+		b[i] = a[i]
+		if i%10 == 0 {
+			b[i] = "changed\n"
+		}
+	}
+	if ops := diffLines(a, b); len(ops) != len(a)+len(a)/10 {
+		t.Errorf("len(diffLines()) = %d, want %d", len(ops), len(a)+len(a)/10)
+	}
+}
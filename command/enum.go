@@ -0,0 +1,371 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"go/constant"
+	"go/types"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/utisam/gogtok/query"
+)
+
+// enumValue is a constant of the enum type.
+type enumValue struct {
+	Name  string
+	Label string
+	Zero  bool
+}
+
+type enumTemplateData struct {
+	Type     string
+	Parse    string
+	Values   string
+	Unsigned bool
+	Bitflag  bool
+	Text     bool
+	JSON     bool
+	SQL      bool
+	Consts   []*enumValue
+	// Zero is the constant whose value is zero for bitflags.
+	Zero *enumValue
+}
+
+// Flags returns non-zero constants of bitflags.
+func (d *enumTemplateData) Flags() []*enumValue {
+	res := []*enumValue{}
+	for _, c := range d.Consts {
+		if !c.Zero {
+			res = append(res, c)
+		}
+	}
+	return res
+}
+
+// FlagNames returns names of non-zero constants.
+func (d *enumTemplateData) FlagNames() []string {
+	res := []string{}
+	for _, c := range d.Flags() {
+		res = append(res, c.Name)
+	}
+	return res
+}
+
+const enumTemplate = `{{$t := .Type -}}
+{{if .Bitflag -}}
+// String returns names of flags joined by "|".
+func (v {{$t}}) String() string {
+	if v == 0 {
+		return {{if .Zero}}{{quote .Zero.Label}}{{else}}"0"{{end}}
+	}
+
+	names := []string{}
+{{- range .Flags}}
+	if v&{{.Name}} == {{.Name}} {
+		names = append(names, {{quote .Label}})
+		v &^= {{.Name}}
+	}
+{{- end}}
+	if v != 0 {
+		names = append(names, "{{$t}}(" + {{if .Unsigned}}strconv.FormatUint(uint64(v), 10){{else}}strconv.FormatInt(int64(v), 10){{end}} + ")")
+	}
+	return strings.Join(names, "|")
+}
+
+// {{.Parse}} parses names of flags joined by "|".
+func {{.Parse}}(s string) ({{$t}}, error) {
+	var v {{$t}}
+{{- if .Zero}}
+	if s == {{quote .Zero.Label}} {
+		return v, nil
+	}
+{{- end}}
+	for _, name := range strings.Split(s, "|") {
+		switch name {
+{{- range .Flags}}
+		case {{quote .Label}}:
+			v |= {{.Name}}
+{{- end}}
+		default:
+			return 0, fmt.Errorf("invalid {{$t}}: %q", s)
+		}
+	}
+	return v, nil
+}
+
+// IsValid reports whether v consists of the flags.
+func (v {{$t}}) IsValid() bool {
+	return v&^({{join .FlagNames " | "}}) == 0
+}
+{{- else}}
+// String returns the name of the constant.
+func (v {{$t}}) String() string {
+	switch v {
+{{- range .Consts}}
+	case {{.Name}}:
+		return {{quote .Label}}
+{{- end}}
+	}
+	return "{{$t}}(" + {{if .Unsigned}}strconv.FormatUint(uint64(v), 10){{else}}strconv.FormatInt(int64(v), 10){{end}} + ")"
+}
+
+// {{.Parse}} returns the {{$t}} named s.
+func {{.Parse}}(s string) ({{$t}}, error) {
+	switch s {
+{{- range .Consts}}
+	case {{quote .Label}}:
+		return {{.Name}}, nil
+{{- end}}
+	}
+	return 0, fmt.Errorf("invalid {{$t}}: %q", s)
+}
+
+// IsValid reports whether v is one of the constants.
+func (v {{$t}}) IsValid() bool {
+	switch v {
+	case {{range $i, $c := .Consts}}{{if $i}}, {{end}}{{$c.Name}}{{end}}:
+		return true
+	}
+	return false
+}
+{{- end}}
+
+// {{.Values}} returns all constants of {{$t}}.
+func {{.Values}}() []{{$t}} {
+	return []{{$t}}{
+{{- range .Consts}}
+		{{.Name}},
+{{- end}}
+	}
+}
+{{- if or .Text .JSON .SQL}}
+
+// MarshalText implements encoding.TextMarshaler.
+func (v {{$t}}) MarshalText() ([]byte, error) {
+	if !v.IsValid() {
+		return nil, fmt.Errorf("invalid {{$t}}: %s", v)
+	}
+	return []byte(v.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (v *{{$t}}) UnmarshalText(b []byte) error {
+	parsed, err := {{.Parse}}(string(b))
+	if err != nil {
+		return err
+	}
+	*v = parsed
+	return nil
+}
+{{- end}}
+{{- if .JSON}}
+
+// MarshalJSON implements json.Marshaler.
+func (v {{$t}}) MarshalJSON() ([]byte, error) {
+	b, err := v.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(b))
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (v *{{$t}}) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	return v.UnmarshalText([]byte(s))
+}
+{{- end}}
+{{- if .SQL}}
+
+// Value implements driver.Valuer.
+func (v {{$t}}) Value() (driver.Value, error) {
+	b, err := v.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan implements sql.Scanner.
+func (v *{{$t}}) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case string:
+		return v.UnmarshalText([]byte(src))
+	case []byte:
+		return v.UnmarshalText(src)
+	}
+	return fmt.Errorf("cannot scan %T into {{$t}}", src)
+}
+{{- end}}
+`
+
+// enumLabel converts the name of the constant into the label.
+func enumLabel(name, trimPrefix, prefix, nameCase string) (string, error) {
+	label := strings.TrimPrefix(name, trimPrefix)
+	switch nameCase {
+	case "":
+	case "lower":
+		label = strings.ToLower(label)
+	case "upper":
+		label = strings.ToUpper(label)
+	case "camel":
+		label = camelCase(label)
+	case "pascal":
+		label = pascalCase(label)
+	case "snake":
+		label = snakeCase(label)
+	case "kebab":
+		label = kebabCase(label)
+	case "upper-snake":
+		label = upperSnakeCase(label)
+	default:
+		return "", fmt.Errorf("unknown case: %s", nameCase)
+	}
+	return prefix + label, nil
+}
+
+// enumConsts returns constants of the type in the order of declarations.
+// Constants having the same value as preceding ones are skipped.
+func enumConsts(pkg *types.Package, named *types.Named) []*types.Const {
+	consts := []*types.Const{}
+	scope := pkg.Scope()
+	for _, name := range scope.Names() {
+		if c, ok := scope.Lookup(name).(*types.Const); ok && types.Identical(c.Type(), named) {
+			consts = append(consts, c)
+		}
+	}
+	sort.Slice(consts, func(i, j int) bool {
+		return consts[i].Pos() < consts[j].Pos()
+	})
+
+	seen := map[string]bool{}
+	res := []*types.Const{}
+	for _, c := range consts {
+		v := c.Val().ExactString()
+		if !seen[v] {
+			seen[v] = true
+			res = append(res, c)
+		}
+	}
+	return res
+}
+
+func newEnum(c *config) *cobra.Command {
+	opts := &generatorOptions{config: c}
+	trimPrefix := ""
+	trimType := false
+	prefix := ""
+	nameCase := ""
+	bitflag := false
+	text := true
+	jsonMethods := false
+	sqlMethods := false
+
+	cmd := &cobra.Command{
+		Use:   "enum TYPE [package]",
+		Short: "Generate methods of the enum type from its constants",
+		Long: `Generate methods of the enum type from its constants.
+
+String, Parse<TYPE>, <TYPE>Values, IsValid, MarshalText and UnmarshalText are generated.
+--json and --sql add MarshalJSON, UnmarshalJSON, Value and Scan.
+With --bitflag, values are combinations of flags joined by "|".
+Constants having the same value as preceding ones are aliases and they are not used as names.`,
+		Example: `  gogtok enum Color --trim-type --case snake -o color_enum.go`,
+		Args:    cobra.RangeArgs(1, 2),
+		RunE: func(_ *cobra.Command, args []string) error {
+			typeName := args[0]
+			dir := "."
+			if len(args) > 1 {
+				dir = args[1]
+			}
+
+			pkg, obj, err := opts.lookupType(dir, typeName)
+			if err != nil {
+				return err
+			}
+			named, ok := obj.Type().(*types.Named)
+			if !ok {
+				return fmt.Errorf("not a named type: %s", typeName)
+			}
+			basic, ok := named.Underlying().(*types.Basic)
+			if !ok || basic.Info()&types.IsInteger == 0 {
+				return fmt.Errorf("underlying type is not an integer: %s", typeName)
+			}
+
+			if trimType {
+				trimPrefix = typeName
+			}
+
+			data := &enumTemplateData{
+				Type:     typeName,
+				Parse:    "Parse" + typeName,
+				Values:   typeName + "Values",
+				Unsigned: basic.Info()&types.IsUnsigned != 0,
+				Bitflag:  bitflag,
+				Text:     text,
+				JSON:     jsonMethods,
+				SQL:      sqlMethods,
+			}
+			if !obj.Exported() {
+				data.Parse = "parse" + upperFirst(typeName)
+			}
+
+			labels := map[string]string{}
+			for _, c := range enumConsts(pkg.Types, named) {
+				label, err := enumLabel(c.Name(), trimPrefix, prefix, nameCase)
+				if err != nil {
+					return err
+				}
+				if other, ok := labels[label]; ok {
+					return fmt.Errorf("%s and %s have the same name: %s", other, c.Name(), label)
+				}
+				labels[label] = c.Name()
+
+				v := &enumValue{
+					Name:  c.Name(),
+					Label: label,
+					Zero:  constant.Sign(c.Val()) == 0,
+				}
+				if v.Zero {
+					data.Zero = v
+				}
+				data.Consts = append(data.Consts, v)
+			}
+			if len(data.Consts) == 0 {
+				return fmt.Errorf("no constants of %s", typeName)
+			}
+			if bitflag && len(data.Flags()) == 0 {
+				return errors.New("no flags except zero")
+			}
+
+			body, err := executeTemplate(enumTemplate, data)
+			if err != nil {
+				return err
+			}
+			// "json" is ambiguous among standard packages
+			imports := []*query.Import{
+				{Path: "encoding/json"},
+				{Path: "database/sql/driver"},
+			}
+			return opts.write(dir, pkg.Types.Name(), body, imports...)
+		},
+	}
+
+	opts.addFlags(cmd)
+	flags := cmd.Flags()
+	flags.StringVar(&trimPrefix, "trim-prefix", trimPrefix, "Prefix to be trimmed from names of constants")
+	flags.BoolVar(&trimType, "trim-type", trimType, "Trim the type name from names of constants")
+	flags.StringVar(&prefix, "prefix", prefix, "Prefix to be added to names")
+	flags.StringVar(&nameCase, "case", nameCase, "Case of names (lower, upper, camel, pascal, snake, kebab, upper-snake)")
+	flags.BoolVar(&bitflag, "bitflag", bitflag, "Values are combinations of flags")
+	flags.BoolVar(&text, "text", text, "Generate MarshalText and UnmarshalText")
+	flags.BoolVar(&jsonMethods, "json", jsonMethods, "Generate MarshalJSON and UnmarshalJSON")
+	flags.BoolVar(&sqlMethods, "sql", sqlMethods, "Generate Value and Scan for database/sql")
+
+	return cmd
+}
@@ -0,0 +1,119 @@
+package command
+
+import "testing"
+
+func TestEnum(t *testing.T) {
+	dir := newTestModule(t, map[string]string{
+		"color.go": `package test
+
+type Color int
+
+const (
+	ColorRed Color = iota
+	ColorDarkBlue
+	ColorDefault = ColorRed
+)
+
+type Perm uint8
+
+const (
+	PermNone Perm = 0
+	PermRead Perm = 1 << iota
+	PermWrite
+)
+`,
+		"color_test.go": `package test
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"testing"
+)
+
+func TestColor(t *testing.T) {
+	if s := ColorDarkBlue.String(); s != "dark_blue" {
+		t.Errorf("String() = %s", s)
+	}
+	if s := Color(9).String(); s != "Color(9)" {
+		t.Errorf("String() = %s", s)
+	}
+	if c, err := ParseColor("red"); err != nil || c != ColorRed {
+		t.Errorf("ParseColor() = %v, %v", c, err)
+	}
+	if _, err := ParseColor("default"); err == nil {
+		t.Error("aliases must not be names")
+	}
+	if len(ColorValues()) != 2 || Color(9).IsValid() {
+		t.Error("ColorValues() or IsValid() is wrong")
+	}
+
+	b, err := json.Marshal(map[string]Color{"c": ColorDarkBlue})
+	if err != nil || string(b) != ` + "`" + `{"c":"dark_blue"}` + "`" + ` {
+		t.Errorf("json.Marshal() = %s, %v", b, err)
+	}
+	var c Color
+	if err := json.Unmarshal([]byte(` + "`" + `"dark_blue"` + "`" + `), &c); err != nil || c != ColorDarkBlue {
+		t.Errorf("json.Unmarshal() = %v, %v", c, err)
+	}
+	if _, err := json.Marshal(Color(9)); err == nil {
+		t.Error("invalid values must not be marshaled")
+	}
+
+	var _ driver.Valuer = c
+	if err := c.Scan([]byte("red")); err != nil || c != ColorRed {
+		t.Errorf("Scan() = %v, %v", c, err)
+	}
+}
+
+func TestPerm(t *testing.T) {
+	if s := (PermRead | PermWrite).String(); s != "read|write" {
+		t.Errorf("String() = %s", s)
+	}
+	if s := PermNone.String(); s != "none" {
+		t.Errorf("String() = %s", s)
+	}
+	if p, err := ParsePerm("write|read"); err != nil || p != PermRead|PermWrite {
+		t.Errorf("ParsePerm() = %v, %v", p, err)
+	}
+	if Perm(8).IsValid() || !(PermRead | PermWrite).IsValid() {
+		t.Error("IsValid() is wrong")
+	}
+}
+`,
+	})
+
+	// Generating over the previous output replaces it
+	for i := 0; i < 2; i++ {
+		generate(t, dir, "color_gen.go", "enum", "Color", "--trim-type", "--case", "snake", "--json", "--sql")
+	}
+	generate(t, dir, "perm_gen.go", "enum", "Perm", "--trim-type", "--case", "snake", "--bitflag")
+	goTest(t, dir)
+}
+
+func TestEnumErrors(t *testing.T) {
+	dir := newTestModule(t, map[string]string{
+		"kind.go": `package test
+
+type Kind int
+
+const (
+	KindFooBar Kind = iota
+	KindFoo_Bar
+)
+
+type Flag int
+
+const FlagNone Flag = 0
+
+type Empty int
+
+type Name string
+`,
+	})
+
+	generateError(t, dir, "KindFooBar and KindFoo_Bar have the same name: foo_bar", "enum", "Kind", "--trim-type", "--case", "snake")
+	generateError(t, dir, "no flags except zero", "enum", "Flag", "--bitflag")
+	generateError(t, dir, "no constants of Empty", "enum", "Empty")
+	generateError(t, dir, "underlying type is not an integer: Name", "enum", "Name")
+	generateError(t, dir, "unknown case: title", "enum", "Kind", "--case", "title")
+}
@@ -0,0 +1,532 @@
+package command
+
+import (
+	"fmt"
+	"go/types"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/utisam/gogtok/query"
+)
+
+// equalMethod is how values of a named type are compared.
+type equalMethod int
+
+const (
+	// equalNone compares fields of the underlying type.
+	equalNone equalMethod = iota
+	// equalValue calls Equal(T) bool.
+	equalValue
+	// equalPointer calls Equal(*T) bool.
+	equalPointer
+)
+
+// equalGenerator generates Equal and Hash of the type
+// and named types in the same package reached from it.
+type equalGenerator struct {
+	pkg  *query.Package
+	opts *generatorOptions
+	// typeName is the name of the type specified in the command line.
+	typeName string
+	imports  *generatorImports
+	tagKey   string
+	nanEqual bool
+	queue    []*types.Named
+	queued   map[*types.Named]bool
+	numVars  int
+	// usesWrite is set if the hash uses the function writing integers.
+	usesWrite bool
+}
+
+// existingMethod returns the method of the named type unless it is declared in files to be replaced.
+func (g *equalGenerator) existingMethod(named *types.Named, name string) *types.Signature {
+	obj, _, _ := types.LookupFieldOrMethod(types.NewPointer(named), true, named.Obj().Pkg(), name)
+	fn, ok := obj.(*types.Func)
+	if !ok || g.opts.replaces(g.pkg, fn, g.typeName) {
+		return nil
+	}
+	return fn.Type().(*types.Signature)
+}
+
+func (g *equalGenerator) existingEqual(named *types.Named) equalMethod {
+	sig := g.existingMethod(named, "Equal")
+	if sig == nil || sig.Params().Len() != 1 || sig.Results().Len() != 1 {
+		return equalNone
+	}
+	if res, ok := sig.Results().At(0).Type().(*types.Basic); !ok || res.Kind() != types.Bool {
+		return equalNone
+	}
+	switch param := sig.Params().At(0).Type(); {
+	case types.Identical(param, named):
+		return equalValue
+	case types.Identical(param, types.NewPointer(named)):
+		return equalPointer
+	}
+	return equalNone
+}
+
+func (g *equalGenerator) existingHash(named *types.Named) bool {
+	sig := g.existingMethod(named, "Hash")
+	if sig == nil || sig.Params().Len() != 0 || sig.Results().Len() != 1 {
+		return false
+	}
+	res, ok := sig.Results().At(0).Type().(*types.Basic)
+	return ok && res.Kind() == types.Uint64
+}
+
+// generated reports whether methods of the named type are generated.
+func (g *equalGenerator) generated(named *types.Named) bool {
+	if named.Obj().Pkg() != g.pkg.Types || g.existingMethod(named, "Equal") != nil {
+		return false
+	}
+	switch named.Underlying().(type) {
+	case *types.Struct, *types.Slice, *types.Map, *types.Array:
+		return !g.simple(named.Underlying())
+	}
+	return false
+}
+
+func (g *equalGenerator) method(named *types.Named) equalMethod {
+	if m := g.existingEqual(named); m != equalNone {
+		return m
+	}
+	if g.generated(named) {
+		return equalPointer
+	}
+	return equalNone
+}
+
+// opaque reports whether the struct of another package has fields which cannot be compared.
+func (g *equalGenerator) opaque(named *types.Named) bool {
+	st, ok := named.Underlying().(*types.Struct)
+	if !ok || named.Obj().Pkg() == g.pkg.Types {
+		return false
+	}
+	for i := 0; i < st.NumFields(); i++ {
+		if !st.Field(i).Exported() {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *equalGenerator) excluded(st *types.Struct, i int) bool {
+	f := st.Field(i)
+	if _, ok := f.Type().Underlying().(*types.Signature); ok {
+		return true
+	}
+	return f.Name() == "_" || reflect.StructTag(st.Tag(i)).Get(g.tagKey) == "-"
+}
+
+// hasInterface reports whether values of the type contain interfaces.
+// == panics if dynamic values of the interfaces are not comparable.
+func hasInterface(t types.Type) bool {
+	switch u := t.Underlying().(type) {
+	case *types.Interface:
+		return true
+	case *types.Array:
+		return hasInterface(u.Elem())
+	case *types.Struct:
+		for i := 0; i < u.NumFields(); i++ {
+			if hasInterface(u.Field(i).Type()) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// simple reports whether values of the type are compared by ==.
+func (g *equalGenerator) simple(t types.Type) bool {
+	if named, ok := t.(*types.Named); ok {
+		if g.method(named) != equalNone {
+			return false
+		}
+		if g.opaque(named) {
+			return types.Comparable(t) && !hasInterface(t)
+		}
+	}
+
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		return u.Info()&(types.IsFloat|types.IsComplex) == 0 || !g.nanEqual
+	case *types.Chan:
+		return true
+	case *types.Array:
+		return g.simple(u.Elem())
+	case *types.Struct:
+		for i := 0; i < u.NumFields(); i++ {
+			if g.excluded(u, i) || !g.simple(u.Field(i).Type()) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func (g *equalGenerator) enqueue(named *types.Named) {
+	if g.generated(named) && !g.queued[named] {
+		g.queued[named] = true
+		g.queue = append(g.queue, named)
+	}
+}
+
+func (g *equalGenerator) newVar(prefix string) string {
+	g.numVars++
+	return fmt.Sprint(prefix, g.numVars)
+}
+
+// equal writes statements returning false if x and y are not equal.
+func (g *equalGenerator) equal(b *strings.Builder, x, y string, t types.Type) {
+	if g.simple(t) {
+		fmt.Fprintf(b, "if %s != %s {\nreturn false\n}\n", x, y)
+		return
+	}
+
+	if named, ok := t.(*types.Named); ok {
+		g.enqueue(named)
+		switch g.method(named) {
+		case equalValue:
+			fmt.Fprintf(b, "if !%s.Equal(%s) {\nreturn false\n}\n", operand(x), y)
+			return
+		case equalPointer:
+			fmt.Fprintf(b, "if !%s.Equal(&%s) {\nreturn false\n}\n", operand(x), y)
+			return
+		}
+		if g.opaque(named) {
+			fmt.Fprintf(b, "if !reflect.DeepEqual(%s, %s) {\nreturn false\n}\n", x, y)
+			return
+		}
+	}
+
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		// NaN is equal to NaN
+		fmt.Fprintf(b, "if %s != %s && !(%s != %s && %s != %s) {\nreturn false\n}\n", x, y, x, x, y, y)
+
+	case *types.Pointer:
+		fmt.Fprintf(b, "if (%s == nil) != (%s == nil) {\nreturn false\n}\n", x, y)
+		elem, _ := u.Elem().(*types.Named)
+		if elem != nil {
+			g.enqueue(elem)
+		}
+		if elem != nil && g.method(elem) == equalPointer {
+			fmt.Fprintf(b, "if %s != nil && !%s.Equal(%s) {\nreturn false\n}\n", x, x, y)
+			return
+		}
+		fmt.Fprintf(b, "if %s != nil {\n", x)
+		g.equal(b, "*"+operand(x), "*"+operand(y), u.Elem())
+		b.WriteString("}\n")
+
+	case *types.Slice:
+		if elem, ok := u.Elem().(*types.Basic); ok && elem.Kind() == types.Byte {
+			fmt.Fprintf(b, "if !bytes.Equal(%s, %s) {\nreturn false\n}\n", x, y)
+			return
+		}
+		fmt.Fprintf(b, "if len(%s) != len(%s) {\nreturn false\n}\n", x, y)
+		g.equalElems(b, x, y, u.Elem())
+
+	case *types.Array:
+		g.equalElems(b, x, y, u.Elem())
+
+	case *types.Map:
+		k, v, w, ok := g.newVar("k"), g.newVar("v"), g.newVar("w"), g.newVar("ok")
+		fmt.Fprintf(b, "if len(%s) != len(%s) {\nreturn false\n}\n", x, y)
+		fmt.Fprintf(b, "for %s, %s := range %s {\n", k, v, x)
+		fmt.Fprintf(b, "%s, %s := %s[%s]\n", w, ok, y, k)
+		fmt.Fprintf(b, "if !%s {\nreturn false\n}\n", ok)
+		g.equal(b, v, w, u.Elem())
+		b.WriteString("}\n")
+
+	case *types.Struct:
+		g.equalFields(b, x, y, u)
+
+	case *types.Interface:
+		// == panics if dynamic values are not comparable
+		fmt.Fprintf(b, "if !reflect.DeepEqual(%s, %s) {\nreturn false\n}\n", x, y)
+
+	case *types.Signature:
+		// Functions are not compared
+
+	default:
+		fmt.Fprintf(b, "if %s != %s {\nreturn false\n}\n", x, y)
+	}
+}
+
+func (g *equalGenerator) equalElems(b *strings.Builder, x, y string, elem types.Type) {
+	i := g.newVar("i")
+	fmt.Fprintf(b, "for %s := range %s {\n", i, x)
+	g.equal(b, operand(x)+"["+i+"]", operand(y)+"["+i+"]", elem)
+	b.WriteString("}\n")
+}
+
+func (g *equalGenerator) equalFields(b *strings.Builder, x, y string, st *types.Struct) {
+	for i := 0; i < st.NumFields(); i++ {
+		if !g.excluded(st, i) {
+			name := st.Field(i).Name()
+			g.equal(b, operand(x)+"."+name, operand(y)+"."+name, st.Field(i).Type())
+		}
+	}
+}
+
+// write writes a statement writing the integer expression to the hash.
+func (g *equalGenerator) write(b *strings.Builder, h, expr string) {
+	g.usesWrite = true
+	fmt.Fprintf(b, "write(%s, %s)\n", h, expr)
+}
+
+// hashValue writes statements writing the value to the hash.
+// Values which are not compared by Equal are not written.
+func (g *equalGenerator) hashValue(b *strings.Builder, h, x string, t types.Type) {
+	if named, ok := t.(*types.Named); ok {
+		g.enqueue(named)
+		switch {
+		case g.existingHash(named) || g.generated(named):
+			g.write(b, h, operand(x)+".Hash()")
+			return
+		case g.existingEqual(named) != equalNone || g.opaque(named):
+			// Hashes may be inconsistent with Equal
+			return
+		}
+	}
+
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		switch {
+		case u.Info()&types.IsBoolean != 0:
+			fmt.Fprintf(b, "if %s {\n", x)
+			g.write(b, h, "1")
+			b.WriteString("}\n")
+		case u.Info()&types.IsString != 0:
+			g.write(b, h, "uint64(len("+x+"))")
+			if _, ok := t.(*types.Named); ok {
+				x = "string(" + x + ")"
+			}
+			fmt.Fprintf(b, "io.WriteString(%s, %s)\n", h, x)
+		case u.Info()&types.IsFloat != 0:
+			g.hashFloat(b, h, "float64("+x+")")
+		case u.Info()&types.IsComplex != 0:
+			g.hashFloat(b, h, "real(complex128("+x+"))")
+			g.hashFloat(b, h, "imag(complex128("+x+"))")
+		case u.Kind() == types.UnsafePointer:
+			g.write(b, h, "uint64(uintptr("+x+"))")
+		default:
+			g.write(b, h, "uint64("+x+")")
+		}
+
+	case *types.Pointer:
+		if elem, ok := u.Elem().(*types.Named); ok && g.generated(elem) {
+			// Generated Hash returns 0 for nil
+			g.enqueue(elem)
+			g.write(b, h, x+".Hash()")
+			return
+		}
+		fmt.Fprintf(b, "if %s != nil {\n", x)
+		g.hashValue(b, h, "*"+operand(x), u.Elem())
+		b.WriteString("}\n")
+
+	case *types.Slice:
+		g.write(b, h, "uint64(len("+x+"))")
+		if elem, ok := u.Elem().(*types.Basic); ok && elem.Kind() == types.Byte {
+			fmt.Fprintf(b, "%s.Write(%s)\n", h, x)
+			return
+		}
+		g.hashElems(b, h, x, u.Elem())
+
+	case *types.Array:
+		g.hashElems(b, h, x, u.Elem())
+
+	case *types.Map:
+		// Hashes of entries are summed up because the order is random
+		sum, k, v, eh := g.newVar("sum"), g.newVar("k"), g.newVar("v"), g.newVar("h")
+		fmt.Fprintf(b, "var %s uint64\n", sum)
+		fmt.Fprintf(b, "for %s, %s := range %s {\n", k, v, x)
+		fmt.Fprintf(b, "%s := fnv.New64a()\n", eh)
+		g.hashValue(b, eh, k, u.Key())
+		g.hashValue(b, eh, v, u.Elem())
+		fmt.Fprintf(b, "%s += %s.Sum64()\n", sum, eh)
+		b.WriteString("}\n")
+		g.write(b, h, sum)
+
+	case *types.Struct:
+		g.hashFields(b, h, x, u)
+	}
+	// Interfaces, functions and channels are not written
+}
+
+func (g *equalGenerator) hashFields(b *strings.Builder, h, x string, st *types.Struct) {
+	for i := 0; i < st.NumFields(); i++ {
+		if !g.excluded(st, i) {
+			name := st.Field(i).Name()
+			g.hashValue(b, h, operand(x)+"."+name, st.Field(i).Type())
+		}
+	}
+}
+
+func (g *equalGenerator) hashElems(b *strings.Builder, h, x string, elem types.Type) {
+	i := g.newVar("i")
+	fmt.Fprintf(b, "for %s := range %s {\n", i, x)
+	g.hashValue(b, h, operand(x)+"["+i+"]", elem)
+	b.WriteString("}\n")
+}
+
+// hashFloat writes the float normalizing -0 and NaN which are equal to 0 and NaN.
+func (g *equalGenerator) hashFloat(b *strings.Builder, h, x string) {
+	f := g.newVar("f")
+	fmt.Fprintf(b, "%s := %s\n", f, x)
+	fmt.Fprintf(b, "if %s == 0 {\n%s = 0\n}\n", f, f)
+	if g.nanEqual {
+		fmt.Fprintf(b, "if %s != %s {\n%s = math.NaN()\n}\n", f, f, f)
+	}
+	g.write(b, h, "math.Float64bits("+f+")")
+}
+
+type equalTemplateData struct {
+	Type      string
+	Equal     string
+	Hash      string
+	UsesWrite bool
+}
+
+const equalTemplate = `
+// Equal reports whether x and other are equal.
+func (x *{{.Type}}) Equal(other *{{.Type}}) bool {
+	if x == nil || other == nil {
+		return x == other
+	}
+{{.Equal -}}
+	return true
+}
+`
+
+const hashTemplate = `
+// Hash returns the hash value of x. Values equal by Equal have the same hash value.
+func (x *{{.Type}}) Hash() uint64 {
+	if x == nil {
+		return 0
+	}
+
+	h := fnv.New64a()
+{{- if .UsesWrite}}
+	write := func(h hash.Hash64, v uint64) {
+		var b [8]byte
+		binary.LittleEndian.PutUint64(b[:], v)
+		h.Write(b[:])
+	}
+{{- end}}
+{{.Hash -}}
+	return h.Sum64()
+}
+`
+
+func newEqual(c *config) *cobra.Command {
+	opts := &generatorOptions{config: c}
+	hash := false
+	nan := "unequal"
+	tagKey := "gogtok"
+
+	cmd := &cobra.Command{
+		Use:   "equal TYPE [package]",
+		Short: "Generate Equal and Hash methods of the type",
+		Long: `Generate Equal and Hash methods of the type.
+
+Slices and arrays are compared element-wise, maps by keys and pointers by pointees.
+Nil and empty slices or maps are equal. Functions and fields tagged with gogtok:"-" are ignored.
+Interfaces are compared by reflect.DeepEqual because == panics if their dynamic values are not comparable.
+Existing Equal(T) or Equal(*T) methods of types are called, even if they are in other packages.
+Named types in the same package reached from the type get the methods too.
+With --hash, Hash() uint64 is generated. It is consistent with Equal.`,
+		Example: `  gogtok equal Point --hash --nan equal -o point_equal.go`,
+		Args:    cobra.RangeArgs(1, 2),
+		RunE: func(_ *cobra.Command, args []string) error {
+			typeName := args[0]
+			dir := "."
+			if len(args) > 1 {
+				dir = args[1]
+			}
+
+			if nan != "equal" && nan != "unequal" {
+				return fmt.Errorf("unknown NaN policy: %s", nan)
+			}
+
+			pkg, obj, err := opts.lookupType(dir, typeName)
+			if err != nil {
+				return err
+			}
+			named, ok := obj.Type().(*types.Named)
+			if !ok {
+				return fmt.Errorf("not a named type: %s", typeName)
+			}
+
+			g := &equalGenerator{
+				pkg:      pkg,
+				opts:     opts,
+				typeName: typeName,
+				imports:  newGeneratorImports(pkg.Types.Path()),
+				tagKey:   tagKey,
+				nanEqual: nan == "equal",
+				queued:   map[*types.Named]bool{},
+			}
+			if g.existingMethod(named, "Equal") != nil {
+				return fmt.Errorf("%s already has Equal", typeName)
+			}
+			if !g.generated(named) {
+				return fmt.Errorf("%s can be compared by ==", typeName)
+			}
+			g.enqueue(named)
+
+			b := &strings.Builder{}
+			for len(g.queue) > 0 {
+				t := g.queue[0]
+				g.queue = g.queue[1:]
+
+				g.numVars = 0
+				g.usesWrite = false
+				data := &equalTemplateData{Type: t.Obj().Name()}
+				text := equalTemplate
+
+				eq := &strings.Builder{}
+				if st, ok := t.Underlying().(*types.Struct); ok {
+					g.equalFields(eq, "x", "other", st)
+				} else {
+					g.equal(eq, "*x", "*other", t.Underlying())
+				}
+				data.Equal = eq.String()
+
+				if hash {
+					h := &strings.Builder{}
+					if st, ok := t.Underlying().(*types.Struct); ok {
+						g.hashFields(h, "h", "x", st)
+					} else {
+						g.hashValue(h, "h", "*x", t.Underlying())
+					}
+					data.Hash = h.String()
+					data.UsesWrite = g.usesWrite
+					text += hashTemplate
+				}
+
+				src, err := executeTemplate(text, data)
+				if err != nil {
+					return err
+				}
+				b.Write(src)
+			}
+
+			imports := g.imports.imports
+			for _, path := range []string{"bytes", "encoding/binary", "hash", "hash/fnv", "io", "math", "reflect"} {
+				imports = append(imports, &query.Import{Path: path})
+			}
+			return opts.write(dir, pkg.Types.Name(), []byte(b.String()), imports...)
+		},
+	}
+
+	opts.addFlags(cmd)
+	flags := cmd.Flags()
+	flags.BoolVar(&hash, "hash", hash, "Generate Hash")
+	flags.StringVar(&nan, "nan", nan, "Policy of NaN (unequal: NaN is not equal to NaN like ==, equal: NaN is equal to NaN)")
+	flags.StringVar(&tagKey, "tag", tagKey, `Key of the tag to ignore fields by "-"`)
+
+	return cmd
+}
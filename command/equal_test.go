@@ -0,0 +1,80 @@
+package command
+
+import "testing"
+
+func TestEqual(t *testing.T) {
+	dir := newTestModule(t, map[string]string{
+		"point.go": `package test
+
+import "time"
+
+type Point struct {
+	X, Y     float64
+	Tags     []string
+	Attrs    map[string]*Point
+	Any      interface{}
+	Created  time.Time
+	Next     *Point
+	Callback func()
+	Ignored  int ` + "`gogtok:\"-\"`" + `
+}
+`,
+		"point_test.go": `package test
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestEqual(t *testing.T) {
+	now := time.Now()
+	p := func() *Point {
+		return &Point{
+			X:       1,
+			Tags:    []string{"a"},
+			Attrs:   map[string]*Point{"a": {Y: 2}},
+			Any:     []int{1},
+			Created: now,
+			Next:    &Point{X: math.NaN()},
+			Ignored: 1,
+		}
+	}
+
+	x, y := p(), p()
+	y.Ignored = 2
+	y.Created = now.In(time.UTC)
+	if !x.Equal(y) || x.Hash() != y.Hash() {
+		t.Errorf("Equal() = false or Hash() differs: %+v, %+v", x, y)
+	}
+
+	y.Any = []int{2}
+	if x.Equal(y) {
+		t.Error("interfaces holding different slices must not be equal")
+	}
+	y = p()
+	y.Attrs["a"].Y = 3
+	if x.Equal(y) {
+		t.Error("maps of different pointees must not be equal")
+	}
+
+	var z *Point
+	if z.Equal(x) || !z.Equal(nil) || z.Hash() != 0 {
+		t.Error("nil is wrong")
+	}
+	if !(&Point{Tags: nil}).Equal(&Point{Tags: []string{}}) {
+		t.Error("nil and empty slices must be equal")
+	}
+	if a, b := (&Point{X: 0}), (&Point{X: math.Copysign(0, -1)}); !a.Equal(b) || a.Hash() != b.Hash() {
+		t.Error("0 and -0 must be equal with the same hash")
+	}
+}
+`,
+	})
+
+	// Previous outputs written through stdout must be replaced
+	for i := 0; i < 2; i++ {
+		generateStdout(t, dir, "point_gen.go", "equal", "Point", "--hash", "--nan", "equal")
+	}
+	goTest(t, dir)
+}
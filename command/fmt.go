@@ -0,0 +1,451 @@
+package command
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/format"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/spf13/cobra"
+	"github.com/utisam/gogtok/query"
+)
+
+// importResolver resolves package names to import paths.
+type importResolver map[string]*query.Import
+
+// newImportResolver creates a resolver with explicit imports.
+// Standard packages are added if their names are unique.
+func newImportResolver(explicit []*query.Import, std bool) (importResolver, error) {
+	r := importResolver{}
+	if std {
+		if err := r.addStandardPackages(); err != nil {
+			return nil, err
+		}
+	}
+	for _, imp := range explicit {
+		r.add(imp)
+	}
+	return r, nil
+}
+
+func (r importResolver) add(imp *query.Import) {
+	name := imp.Name
+	if name == "" {
+		name = assumedPackageName(imp.Path)
+	}
+	if name == "_" || name == "." {
+		return
+	}
+	r[name] = imp
+}
+
+func (r importResolver) addStandardPackages() error {
+	importPaths, err := standardPackages()
+	if err != nil {
+		return err
+	}
+
+	paths := map[string][]string{}
+	for _, importPath := range importPaths {
+		pkgName := assumedPackageName(importPath)
+		paths[pkgName] = append(paths[pkgName], importPath)
+	}
+
+	for name, ps := range paths {
+		if len(ps) == 1 {
+			r[name] = &query.Import{Path: ps[0]}
+		}
+	}
+	return nil
+}
+
+// standardPackages returns import paths of standard packages except internal and cmd packages.
+func standardPackages() ([]string, error) {
+	root := filepath.Join(build.Default.GOROOT, "src")
+
+	res := []string{}
+	err := filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return nil
+		}
+
+		name := info.Name()
+		if p != root && (name == "cmd" || name == "internal" || name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+			return filepath.SkipDir
+		}
+
+		if p == root {
+			return nil
+		}
+		matches, err := filepath.Glob(filepath.Join(p, "*.go"))
+		if err != nil || len(matches) == 0 {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		res = append(res, filepath.ToSlash(rel))
+		return nil
+	})
+	return res, err
+}
+
+// assumedPackageName guesses the package name from the import path like goimports.
+func assumedPackageName(importPath string) string {
+	elems := strings.Split(importPath, "/")
+	name := elems[len(elems)-1]
+	if len(elems) > 1 && isVersionElement(name) {
+		name = elems[len(elems)-2]
+	}
+
+	if i := strings.IndexAny(name, ".-"); i >= 0 {
+		if strings.HasPrefix(name, "go-") {
+			name = name[len("go-"):]
+		}
+		if i := strings.LastIndex(name, ".v"); i >= 0 && isVersionElement(name[i+1:]) {
+			name = name[:i]
+		}
+		name = strings.TrimSuffix(strings.TrimSuffix(name, "-go"), ".go")
+	}
+
+	return strings.Map(func(r rune) rune {
+		if r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, name)
+}
+
+func isVersionElement(s string) bool {
+	if len(s) < 2 || s[0] != 'v' {
+		return false
+	}
+	_, err := strconv.Atoi(s[1:])
+	return err == nil
+}
+
+// syntaxError is a syntax error with lines of the source.
+type syntaxError struct {
+	list    scanner.ErrorList
+	context string
+}
+
+func (e *syntaxError) Error() string {
+	return e.list.Error()
+}
+
+// sourceError adds lines of the source to the syntax error.
+func sourceError(src []byte, err error) error {
+	list, ok := err.(scanner.ErrorList)
+	if !ok {
+		return err
+	}
+	list.RemoveMultiples()
+
+	lines := bytes.Split(src, []byte("\n"))
+	b := &strings.Builder{}
+	for i, e := range list {
+		if i >= 10 {
+			fmt.Fprintf(b, "(%d more errors)\n", len(list)-i)
+			break
+		}
+
+		fmt.Fprintf(b, "%s\n", e)
+		for l := e.Pos.Line - 2; l <= e.Pos.Line; l++ {
+			if l >= 1 && l <= len(lines) {
+				fmt.Fprintf(b, "%5d | %s\n", l, lines[l-1])
+			}
+		}
+		if e.Pos.Column > 0 {
+			fmt.Fprintf(b, "      | %s^\n", strings.Repeat(" ", e.Pos.Column-1))
+		}
+	}
+	return &syntaxError{
+		list:    list,
+		context: b.String(),
+	}
+}
+
+// isStandardPackage reports whether the path is a standard package which has no domain.
+func isStandardPackage(importPath string) bool {
+	elem := strings.SplitN(importPath, "/", 2)[0]
+	return !strings.Contains(elem, ".")
+}
+
+// packageRefs returns names used as qualifiers such as "fmt" in "fmt.Println" which are not declared in the file.
+func packageRefs(f *ast.File) map[string]bool {
+	refs := map[string]bool{}
+	ast.Inspect(f, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		if x, ok := sel.X.(*ast.Ident); ok && x.Obj == nil {
+			refs[x.Name] = true
+		}
+		return true
+	})
+	return refs
+}
+
+// packageScope returns names declared at the package level in other files of the package in the directory.
+// Test files are included only if the file is a test file like "go test".
+func packageScope(filename string, f *ast.File) map[string]bool {
+	names := map[string]bool{}
+	matches, _ := filepath.Glob(filepath.Join(filepath.Dir(filename), "*.go"))
+	isTest := strings.HasSuffix(filename, "_test.go")
+	for _, match := range matches {
+		if filepath.Base(match) == filepath.Base(filename) || (!isTest && strings.HasSuffix(match, "_test.go")) {
+			continue
+		}
+		other, err := parser.ParseFile(token.NewFileSet(), match, nil, 0)
+		if err != nil || other.Name.Name != f.Name.Name {
+			continue
+		}
+		for name := range other.Scope.Objects {
+			names[name] = true
+		}
+	}
+	return names
+}
+
+// importSpec is an import with comments attached to it in the source.
+type importSpec struct {
+	*query.Import
+	// Doc is lines of comments above the spec.
+	Doc []string
+	// Comment is the comment following the spec in the line.
+	Comment string
+}
+
+// commentLines returns lines of the comment group.
+func commentLines(g *ast.CommentGroup) []string {
+	if g == nil {
+		return nil
+	}
+	res := make([]string, len(g.List))
+	for i, c := range g.List {
+		res[i] = c.Text
+	}
+	return res
+}
+
+// renderImportSpecs writes the import declaration with comments.
+// Standard packages are separated with a blank line so that gofmt does not sort them with others.
+func renderImportSpecs(b *bytes.Buffer, specs []*importSpec) {
+	if len(specs) == 0 {
+		return
+	}
+	if len(specs) == 1 && len(specs[0].Doc) == 0 {
+		b.WriteString("import " + specs[0].String())
+		if specs[0].Comment != "" {
+			b.WriteString(" " + specs[0].Comment)
+		}
+		b.WriteString("\n")
+		return
+	}
+
+	b.WriteString("import (\n")
+	for i, spec := range specs {
+		if i > 0 && isStandardPackage(specs[i-1].Path) && !isStandardPackage(spec.Path) {
+			b.WriteString("\n")
+		}
+		for _, line := range spec.Doc {
+			b.WriteString("\t" + line + "\n")
+		}
+		b.WriteString("\t" + spec.String())
+		if spec.Comment != "" {
+			b.WriteString(" " + spec.Comment)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString(")\n")
+}
+
+// importName returns the name referring the imported package in the file.
+func importName(spec *ast.ImportSpec, importPath string) string {
+	if spec.Name != nil {
+		return spec.Name.Name
+	}
+	return assumedPackageName(importPath)
+}
+
+// formatSource removes unused imports, adds missing imports resolved by the resolver and formats the source.
+func formatSource(filename string, src []byte, resolver importResolver) ([]byte, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, filename, src, parser.ParseComments|parser.AllErrors)
+	if err != nil {
+		return nil, sourceError(src, err)
+	}
+
+	srcDir := filepath.Dir(filename)
+	refs := packageRefs(f)
+	// Identifiers declared in other files are not packages
+	for name := range packageScope(filename, f) {
+		delete(refs, name)
+	}
+	imported := map[string]bool{}
+	imports := []*importSpec{}
+	for _, spec := range f.Imports {
+		importPath, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		imp := &query.Import{Path: importPath}
+		if spec.Name != nil {
+			imp.Name = spec.Name.Name
+		}
+
+		name := importName(spec, importPath)
+		if name != "_" && name != "." && !refs[name] {
+			// Check the actual package name before removing it
+			pkg, err := build.Import(importPath, srcDir, 0)
+			if err != nil || !refs[pkg.Name] {
+				continue
+			}
+			name = pkg.Name
+		}
+
+		imported[name] = true
+		imports = append(imports, &importSpec{
+			Import:  imp,
+			Doc:     commentLines(spec.Doc),
+			Comment: strings.Join(commentLines(spec.Comment), " "),
+		})
+	}
+
+	names := make([]string, 0, len(refs))
+	for name := range refs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if imported[name] {
+			continue
+		}
+		if imp, ok := resolver[name]; ok {
+			imports = append(imports, &importSpec{Import: imp})
+			imported[name] = true
+		}
+	}
+
+	sort.SliceStable(imports, func(i, j int) bool {
+		si, sj := isStandardPackage(imports[i].Path), isStandardPackage(imports[j].Path)
+		if si != sj {
+			return si
+		}
+		return imports[i].Path < imports[j].Path
+	})
+
+	importDecl := &bytes.Buffer{}
+	renderImportSpecs(importDecl, imports)
+
+	// Replace import declarations with the rendered one
+	start, end := fset.Position(f.Name.End()).Offset, fset.Position(f.Name.End()).Offset
+	for _, decl := range f.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.IMPORT {
+			break
+		}
+		if start == fset.Position(f.Name.End()).Offset {
+			start = fset.Position(genDecl.Pos()).Offset
+		}
+		end = fset.Position(genDecl.End()).Offset
+		// The comment of the last spec follows the declaration without parentheses
+		for _, spec := range genDecl.Specs {
+			if c := spec.(*ast.ImportSpec).Comment; c != nil && fset.Position(c.End()).Offset > end {
+				end = fset.Position(c.End()).Offset
+			}
+		}
+	}
+
+	b := &bytes.Buffer{}
+	b.Write(src[:start])
+	if start == end {
+		b.WriteString("\n\n")
+	}
+	b.Write(importDecl.Bytes())
+	b.Write(src[end:])
+
+	res, err := format.Source(b.Bytes())
+	if err != nil {
+		return nil, sourceError(b.Bytes(), err)
+	}
+	return res, nil
+}
+
+func newFmt(c *config) *cobra.Command {
+	imports := []string{}
+	std := true
+	filename := "<stdin>"
+
+	cmd := &cobra.Command{
+		Use:   "fmt",
+		Short: "Format Go source from stdin and fix imports",
+		Long: `Format Go source from stdin and fix imports.
+
+Unused imports are removed and missing imports are added by the resolution table.
+The table consists of standard packages whose names are unique, "imports" in ` + configFileName + ` and packages specified by --import.
+Packages which have the same name such as "text/template" and "html/template" must be specified.
+Names declared in other files of the package in the directory of --filename are not resolved as packages.
+Comments of imports are kept unless the imports are removed.`,
+		Example: `  render_file | gogtok fmt --import 'text/template' --import 'yaml "gopkg.in/yaml.v2"' > file.go`,
+		Args:    cobra.NoArgs,
+		RunE: func(_ *cobra.Command, args []string) error {
+			explicit := c.imports()
+			for _, s := range imports {
+				imp, err := query.ParseImport(s)
+				if err != nil {
+					return err
+				}
+				explicit = append(explicit, imp)
+			}
+
+			resolver, err := newImportResolver(explicit, std)
+			if err != nil {
+				return err
+			}
+
+			src, err := ioutil.ReadAll(os.Stdin)
+			if err != nil {
+				return err
+			}
+
+			res, err := formatSource(filename, src, resolver)
+			if err, ok := err.(*syntaxError); ok {
+				io.WriteString(os.Stderr, err.context)
+			}
+			if err != nil {
+				return err
+			}
+
+			_, err = io.Copy(os.Stdout, bytes.NewReader(res))
+			return err
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringArrayVarP(&imports, "import", "i", imports, `Package to resolve missing imports (e.g. 'text/template' or 'name "path"')`)
+	flags.BoolVar(&std, "std", std, "Resolve standard packages whose names are unique")
+	flags.StringVar(&filename, "filename", filename, "File name used in error messages and to find packages")
+
+	return cmd
+}
@@ -0,0 +1,173 @@
+package command
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/utisam/gogtok/query"
+)
+
+func TestFormatSource(t *testing.T) {
+	resolver := importResolver{
+		"strings": {Path: "strings"},
+		"yaml":    {Path: "gopkg.in/yaml.v2"},
+		"client":  {Path: "example.com/client"},
+	}
+
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{
+			name: "comment of a single import",
+			src:  "package p\n\nimport \"fmt\" // why\n\nvar _ = fmt.Sprint\n",
+			want: "package p\n\nimport \"fmt\" // why\n\nvar _ = fmt.Sprint\n",
+		},
+		{
+			name: "comments in the group",
+			src: `package p
+
+import (
+	// Doc of fmt
+	"fmt" // why
+	"os" // unused
+)
+
+var _ = fmt.Sprint
+var _ = strings.TrimSpace
+`,
+			want: `package p
+
+import (
+	// Doc of fmt
+	"fmt" // why
+	"strings"
+)
+
+var _ = fmt.Sprint
+var _ = strings.TrimSpace
+`,
+		},
+		{
+			name: "alias imports",
+			src: `package p
+
+import (
+	y "gopkg.in/yaml.v2"
+	unused "gopkg.in/unused.v1"
+	_ "embed"
+)
+
+var _ = y.Marshal
+`,
+			want: `package p
+
+import (
+	_ "embed"
+
+	y "gopkg.in/yaml.v2"
+)
+
+var _ = y.Marshal
+`,
+		},
+		{
+			name: "standard packages are separated",
+			src:  "package p\n\nvar _ = yaml.Marshal\nvar _ = strings.TrimSpace\n",
+			want: "package p\n\nimport (\n\t\"strings\"\n\n\t\"gopkg.in/yaml.v2\"\n)\n\nvar _ = yaml.Marshal\nvar _ = strings.TrimSpace\n",
+		},
+		{
+			name: "identifiers declared in the file",
+			src:  "package p\n\nvar client struct{ Name string }\n\nvar _ = client.Name\n",
+			want: "package p\n\nvar client struct{ Name string }\n\nvar _ = client.Name\n",
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := formatSource("p.go", []byte(tt.src), resolver)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if string(got) != tt.want {
+			t.Errorf("%s: formatSource() = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFormatSourcePackageScope(t *testing.T) {
+	dir := t.TempDir()
+	if err := ioutil.WriteFile(filepath.Join(dir, "client.go"), []byte("package p\n\nvar client struct{ Name string }\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "other_test.go"), []byte("package p\n\nvar yaml struct{ Name string }\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	resolver := importResolver{
+		"client": {Path: "example.com/client"},
+		"yaml":   {Path: "gopkg.in/yaml.v2"},
+	}
+	src := "package p\n\nvar _ = client.Name\nvar _ = yaml.Marshal\n"
+	want := "package p\n\nimport \"gopkg.in/yaml.v2\"\n\nvar _ = client.Name\nvar _ = yaml.Marshal\n"
+
+	got, err := formatSource(filepath.Join(dir, "p.go"), []byte(src), resolver)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Errorf("formatSource() = %s, want %s", got, want)
+	}
+}
+
+func TestImportResolver(t *testing.T) {
+	r, err := newImportResolver([]*query.Import{
+		{Path: "text/template"},
+		{Name: "yaml", Path: "gopkg.in/yaml.v2"},
+		{Name: "_", Path: "embed"},
+	}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		path string
+	}{
+		{"fmt", "fmt"},
+		{"template", "text/template"},
+		{"yaml", "gopkg.in/yaml.v2"},
+		{"rand", ""},
+		{"_", ""},
+	}
+	for _, tt := range tests {
+		path := ""
+		if imp, ok := r[tt.name]; ok {
+			path = imp.Path
+		}
+		if path != tt.path {
+			t.Errorf("resolver[%q] = %q, want %q", tt.name, path, tt.path)
+		}
+	}
+}
+
+func TestAssumedPackageName(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"fmt", "fmt"},
+		{"gopkg.in/yaml.v2", "yaml"},
+		{"github.com/go-sql-driver/mysql", "mysql"},
+		{"github.com/mattn/go-sqlite3", "sqlite3"},
+		{"github.com/utisam/gogtok/v2", "gogtok"},
+		{"github.com/foo/bar-go", "bar"},
+	}
+	for _, tt := range tests {
+		if got := assumedPackageName(tt.path); got != tt.want {
+			t.Errorf("assumedPackageName(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
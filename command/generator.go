@@ -0,0 +1,265 @@
+package command
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/types"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/spf13/cobra"
+	"github.com/utisam/gogtok/query"
+)
+
+// generatorOptions are options common to generator commands such as "gogtok enum".
+type generatorOptions struct {
+	config *config
+	// command is the name of the generator command.
+	command  string
+	output   string
+	fragment bool
+}
+
+func (o *generatorOptions) addFlags(cmd *cobra.Command) {
+	o.command = cmd.Name()
+	flags := cmd.Flags()
+	flags.StringVarP(&o.output, "output", "o", o.output, "File to write if it is changed instead of stdout")
+	flags.BoolVar(&o.fragment, "fragment", o.fragment, "Print only declarations to be embedded in other files")
+}
+
+// generatorFuncs are functions available in templates of generators.
+var generatorFuncs = template.FuncMap{
+	"lower":      strings.ToLower,
+	"upper":      strings.ToUpper,
+	"camel":      camelCase,
+	"pascal":     pascalCase,
+	"snake":      snakeCase,
+	"kebab":      kebabCase,
+	"upperSnake": upperSnakeCase,
+	"join":       strings.Join,
+	"quote":      strconv.Quote,
+}
+
+// generatorImports qualifies types by names of imported packages for generated files.
+type generatorImports struct {
+	// pkgPath is the path of the package to generate the file in.
+	pkgPath string
+	names   map[string]string
+	used    map[string]bool
+	imports []*query.Import
+}
+
+func newGeneratorImports(pkgPath string) *generatorImports {
+	return &generatorImports{
+		pkgPath: pkgPath,
+		names:   map[string]string{},
+		used:    map[string]bool{},
+	}
+}
+
+// qualifier returns the name of the package and imports it.
+// Names are suffixed with numbers if they conflict.
+func (g *generatorImports) qualifier(pkg *types.Package) string {
+	if pkg.Path() == g.pkgPath {
+		return ""
+	}
+	if name, ok := g.names[pkg.Path()]; ok {
+		return name
+	}
+
+	name := pkg.Name()
+	for i := 2; g.used[name]; i++ {
+		name = fmt.Sprint(pkg.Name(), i)
+	}
+	g.used[name] = true
+	g.names[pkg.Path()] = name
+
+	imp := &query.Import{Path: pkg.Path()}
+	if name != assumedPackageName(pkg.Path()) {
+		imp.Name = name
+	}
+	g.imports = append(g.imports, imp)
+	return name
+}
+
+// lookupType loads the package in the directory and looks up the type declared in it.
+func (o *generatorOptions) lookupType(dir, typeName string) (*query.Package, *types.TypeName, error) {
+	pkgs, err := loadPackages(o.config.queryConfig(false), dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	pkg := pkgs[0]
+
+	obj, ok := pkg.Types.Scope().Lookup(typeName).(*types.TypeName)
+	if !ok {
+		return nil, nil, fmt.Errorf("type not found: %s", typeName)
+	}
+	return pkg, obj, nil
+}
+
+// fieldTag returns the value of the key in the tag of the field like "tag[key]" columns.
+func fieldTag(st *types.Struct, i int, key string) string {
+	f := &query.Field{Tag: st.Tag(i)}
+	return f.TagValue(key)
+}
+
+// declaredIn reports whether the object is declared in the file.
+func declaredIn(pkg *query.Package, obj types.Object, filename string) bool {
+	if filename == "" {
+		return false
+	}
+	absFilename, err := filepath.Abs(filename)
+	if err != nil {
+		return false
+	}
+	absDecl, err := filepath.Abs(pkg.Fset.Position(obj.Pos()).Filename)
+	return err == nil && absDecl == absFilename
+}
+
+// generatedHeaderPattern matches the header written by generators and captures arguments of gogtok.
+var generatedHeaderPattern = regexp.MustCompile(`^// Code generated by "gogtok (.*)"\. DO NOT EDIT\.$`)
+
+// generatedFor reports whether the file has the header written by the command for the type
+// such as "// Code generated by "gogtok accessors Config --setters". DO NOT EDIT.".
+func generatedFor(f *ast.File, command, typeName string) bool {
+	for _, cg := range f.Comments {
+		if cg.Pos() > f.Package {
+			break
+		}
+		for _, c := range cg.List {
+			m := generatedHeaderPattern.FindStringSubmatch(c.Text)
+			if m == nil {
+				continue
+			}
+			words := strings.Fields(m[1])
+			if len(words) == 0 || words[0] != command {
+				return false
+			}
+			for _, word := range words[1:] {
+				if word == typeName {
+					return true
+				}
+			}
+			return false
+		}
+	}
+	return false
+}
+
+// replaces reports whether the object is declared in a file to be replaced by the output of the generator for the type.
+// It is the output file if it is specified.
+// Otherwise they are files generated by the same command for the type,
+// which are previous outputs written through stdout such as "gogtok accessors T | gogtok write t.go".
+func (o *generatorOptions) replaces(pkg *query.Package, obj types.Object, typeName string) bool {
+	if o.output != "" {
+		return declaredIn(pkg, obj, o.output)
+	}
+	f := pkg.File(pkg.Fset.Position(obj.Pos()).Filename)
+	return f != nil && generatedFor(f, o.command, typeName)
+}
+
+// zeroValue returns the expression of the zero value of the type.
+func zeroValue(t types.Type, qualifier types.Qualifier) string {
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		switch {
+		case u.Info()&types.IsString != 0:
+			return `""`
+		case u.Info()&types.IsBoolean != 0:
+			return "false"
+		case u.Kind() == types.UnsafePointer:
+			return "nil"
+		}
+		return "0"
+	case *types.Pointer, *types.Slice, *types.Map, *types.Signature, *types.Chan, *types.Interface:
+		return "nil"
+	}
+	return types.TypeString(t, qualifier) + "{}"
+}
+
+// zeroCondition returns the condition that the expression is the zero value.
+func zeroCondition(expr string, t types.Type, qualifier types.Qualifier) (string, error) {
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		switch {
+		case u.Info()&types.IsString != 0:
+			return expr + ` == ""`, nil
+		case u.Info()&types.IsBoolean != 0:
+			return "!" + expr, nil
+		case u.Kind() == types.UnsafePointer:
+			return expr + " == nil", nil
+		}
+		return expr + " == 0", nil
+	case *types.Pointer, *types.Slice, *types.Map, *types.Signature, *types.Chan, *types.Interface:
+		return expr + " == nil", nil
+	}
+	if types.Comparable(t) {
+		return expr + " == (" + types.TypeString(t, qualifier) + "{})", nil
+	}
+	return "", fmt.Errorf("cannot check whether %s is set", expr)
+}
+
+// write writes the generated declarations as a file of the package in the directory.
+// Imports are resolved from standard packages, the configuration and the given imports.
+func (o *generatorOptions) write(pkgDir, pkgName string, body []byte, imports ...*query.Import) error {
+	src, err := o.render(pkgDir, pkgName, body, imports)
+	if err, ok := err.(*syntaxError); ok {
+		io.WriteString(os.Stderr, err.context)
+	}
+	if err != nil {
+		return err
+	}
+
+	if o.output != "" {
+		return writeOutput(o.output, src)
+	}
+	_, err = os.Stdout.Write(src)
+	return err
+}
+
+func (o *generatorOptions) render(pkgDir, pkgName string, body []byte, imports []*query.Import) ([]byte, error) {
+	if o.fragment {
+		src, err := format.Source(body)
+		if err != nil {
+			return nil, sourceError(body, err)
+		}
+		return src, nil
+	}
+
+	b := &bytes.Buffer{}
+	fmt.Fprintf(b, "// Code generated by \"gogtok %s\". DO NOT EDIT.\n\n", strings.Join(os.Args[1:], " "))
+	fmt.Fprintf(b, "package %s\n\n", pkgName)
+	b.Write(body)
+
+	resolver, err := newImportResolver(append(o.config.imports(), imports...), true)
+	if err != nil {
+		return nil, err
+	}
+
+	filename := o.output
+	if filename == "" {
+		filename = filepath.Join(pkgDir, "<stdout>")
+	}
+	return formatSource(filename, b.Bytes(), resolver)
+}
+
+// executeTemplate renders the template of the generator.
+func executeTemplate(text string, data interface{}) ([]byte, error) {
+	tmpl, err := template.New("").Funcs(generatorFuncs).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &bytes.Buffer{}
+	if err := tmpl.Execute(b, data); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
@@ -0,0 +1,131 @@
+package command
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// newTestModule writes the files into a temporary module and returns its directory.
+func newTestModule(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	files["go.mod"] = "module example.com/test\n\ngo 1.18\n"
+	for name, content := range files {
+		filename := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filename, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// runGogtok runs the command with the arguments.
+// os.Args is replaced while running because generators write it into headers.
+func runGogtok(args ...string) error {
+	defer func(osArgs []string) {
+		os.Args = osArgs
+	}(os.Args)
+	os.Args = append([]string{"gogtok"}, args...)
+
+	cmd := New()
+	cmd.SetArgs(args)
+	cmd.SilenceErrors = true
+	cmd.SilenceUsage = true
+	return cmd.Execute()
+}
+
+// generateError runs the generator in the module and checks that it fails with the error.
+func generateError(t *testing.T, dir, want string, args ...string) {
+	t.Helper()
+
+	err := runGogtok(append(args, dir)...)
+	if err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("gogtok %s: %v, want %q", strings.Join(args, " "), err, want)
+	}
+}
+
+// generate runs the generator in the module and writes the output into the file.
+func generate(t *testing.T, dir, filename string, args ...string) {
+	t.Helper()
+
+	args = append(args, dir, "-o", filepath.Join(dir, filename))
+	if err := runGogtok(args...); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// gogtokStdout runs the command with the arguments and returns its stdout.
+func gogtokStdout(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+
+	out, err := ioutil.TempFile(t.TempDir(), "stdout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer out.Close()
+
+	stdout := os.Stdout
+	os.Stdout = out
+	err = runGogtok(args...)
+	os.Stdout = stdout
+
+	b, readErr := ioutil.ReadFile(out.Name())
+	if readErr != nil {
+		t.Fatal(readErr)
+	}
+	return string(b), err
+}
+
+// generateStdout runs the generator in the module and writes its stdout into the file
+// like "gogtok COMMAND | gogtok write FILE".
+func generateStdout(t *testing.T, dir, filename string, args ...string) {
+	t.Helper()
+
+	out, err := gogtokStdout(t, append(args, dir)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, filename), []byte(out), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// goTest runs tests of the module to check that generated code compiles and works.
+func goTest(t *testing.T, dir string) {
+	t.Helper()
+
+	if testing.Short() {
+		t.Skip("go test is skipped in short mode")
+	}
+	goCmd, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip(err)
+	}
+
+	cmd := exec.Command(goCmd, "test", "-count=1", "./...")
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		generated, _ := filepath.Glob(filepath.Join(dir, "*_gen.go"))
+		nested, _ := filepath.Glob(filepath.Join(dir, "*", "*_gen.go"))
+		generated = append(generated, nested...)
+		for _, filename := range generated {
+			b, _ := ioutil.ReadFile(filename)
+			t.Logf("%s:\n%s", filepath.Base(filename), b)
+		}
+		t.Fatalf("go test: %v\n%s", err, out)
+	}
+}
+
+// regexpMatch reports whether the source matches the pattern.
+func regexpMatch(pattern string, src []byte) bool {
+	return regexp.MustCompile(pattern).Match(src)
+}
@@ -1,6 +1,8 @@
 package command
 
 import (
+	"errors"
+	"fmt"
 	"io"
 	"os"
 	"text/template"
@@ -21,39 +23,85 @@ const (
 	convertLineTemplate       = `{{.Field}}: {{.Converter}}({{.From}}.{{.Field}})`
 )
 
-func newGlue() *cobra.Command {
-	lineTemplate := `{{.Field}}: {{.From}}.{{.Field}},`
+// defaultLineTemplate returns the line template for the destination and the converter.
+func defaultLineTemplate(to, converter string) string {
+	switch {
+	case to != "" && converter != "":
+		return assignConvertLineTemplate
+	case to != "":
+		return assignLineTemplate
+	case converter != "":
+		return convertLineTemplate
+	}
+	return `{{.Field}}: {{.From}}.{{.Field}},`
+}
+
+func newGlue(c *config) *cobra.Command {
+	lineTemplate := defaultLineTemplate("", "")
+	templateName := ""
 	to := ""
 	converter := ""
+	typed := false
 
 	cmd := &cobra.Command{
 		Use:   "glue",
 		Short: "Generate glue code",
-		Args:  cobra.MinimumNArgs(1),
+		Long: `Generate glue code.
+
+--template selects a line template in "templates" of ` + configFileName + `.
+With --typed, fields are pairs of names and types such as output of "gogtok list fields --columns name,type",
+and converters are selected by the types from "converters" of ` + configFileName + ` unless --converter is specified.`,
+		Args: cobra.MinimumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			from := args[0]
 			fields := args[1:]
 
-			if !cmd.Flag("line-template").Changed {
-				if to != "" && converter != "" {
-					lineTemplate = assignConvertLineTemplate
-				} else if to != "" && converter == "" {
-					lineTemplate = assignLineTemplate
-				} else if to == "" && converter != "" {
-					lineTemplate = convertLineTemplate
+			if templateName != "" {
+				t, ok := c.Templates[templateName]
+				if !ok {
+					return fmt.Errorf("template not found in %s: %s", configFileName, templateName)
 				}
+				lineTemplate = t
+			}
+			fixedTemplate := templateName != "" || cmd.Flag("line-template").Changed
+
+			converters := make([]string, len(fields))
+			for i := range converters {
+				converters[i] = converter
 			}
+			if typed {
+				if len(fields)%2 != 0 {
+					return errors.New("fields must be pairs of names and types with --typed")
+				}
 
-			tmpl, err := template.New("").Parse(lineTemplate)
-			if err != nil {
-				return err
+				names := []string{}
+				converters = []string{}
+				for i := 0; i < len(fields); i += 2 {
+					names = append(names, fields[i])
+					if converter != "" {
+						converters = append(converters, converter)
+					} else {
+						converters = append(converters, c.Converters[fields[i+1]])
+					}
+				}
+				fields = names
 			}
 
-			for _, field := range fields {
+			for i, field := range fields {
+				t := lineTemplate
+				if !fixedTemplate {
+					t = defaultLineTemplate(to, converters[i])
+				}
+
+				tmpl, err := template.New("").Parse(t)
+				if err != nil {
+					return err
+				}
+
 				tmpl.Execute(os.Stdout, &glueTemplateData{
 					From:      from,
 					To:        to,
-					Converter: converter,
+					Converter: converters[i],
 					Field:     field,
 				})
 				io.WriteString(os.Stdout, "\n")
@@ -64,8 +112,10 @@ func newGlue() *cobra.Command {
 
 	flags := cmd.Flags()
 	flags.StringVar(&lineTemplate, "line-template", lineTemplate, "Template to render each line")
+	flags.StringVar(&templateName, "template", templateName, "Name of the line template in "+configFileName)
 	flags.StringVar(&to, "to", to, `Shorthand for --line-template="`+assignLineTemplate+`"`)
 	flags.StringVar(&converter, "converter", converter, `Shorthand for --line-template="`+convertLineTemplate+`"`)
+	flags.BoolVar(&typed, "typed", typed, "Fields are pairs of names and types to select converters")
 
 	return cmd
 }
@@ -1,68 +1,12 @@
 package command
 
 import (
-	"io"
 	"os"
-	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/utisam/gogtok/query"
 )
 
-type importPackage struct {
-	Name string
-	Path string
-}
-
-func (pkg *importPackage) String() string {
-	b := strings.Builder{}
-	if pkg.Name != "" {
-		b.WriteString(pkg.Name)
-		b.WriteRune(' ')
-	}
-	b.WriteRune('"')
-	b.WriteString(pkg.Path)
-	b.WriteRune('"')
-
-	return b.String()
-}
-
-func parseImportPackage(s string) (*importPackage, error) {
-	s = strings.TrimSpace(s)
-
-	pair := strings.SplitN(s, " ", 2)
-	if len(pair) == 1 {
-		return &importPackage{
-			Path: strings.Trim(s, `"`),
-		}, nil
-	}
-
-	return &importPackage{
-		Name: pair[0],
-		Path: strings.Trim(pair[1], `"`),
-	}, nil
-}
-
-func renderImport(w io.Writer, pkgs []*importPackage) (err error) {
-	switch len(pkgs) {
-	case 0:
-		return nil
-	case 1:
-		io.WriteString(w, "import ")
-		io.WriteString(w, pkgs[0].String())
-		io.WriteString(w, "\n")
-		return nil
-	}
-
-	io.WriteString(w, "import (\n")
-	for _, pkg := range pkgs {
-		io.WriteString(w, "\t")
-		io.WriteString(w, pkg.String())
-		io.WriteString(w, "\n")
-	}
-	io.WriteString(w, ")\n")
-	return nil
-}
-
 func newImport() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "import",
@@ -72,9 +16,9 @@ func newImport() *cobra.Command {
 				return nil
 			}
 
-			pkgs := make([]*importPackage, len(args))
+			pkgs := make([]*query.Import, len(args))
 			for i, s := range args {
-				pkg, err := parseImportPackage(s)
+				pkg, err := query.ParseImport(s)
 				if err != nil {
 					return err
 				}
@@ -82,7 +26,7 @@ func newImport() *cobra.Command {
 				pkgs[i] = pkg
 			}
 
-			return renderImport(os.Stdout, pkgs)
+			return query.RenderImport(os.Stdout, pkgs)
 		},
 	}
 
@@ -0,0 +1,165 @@
+package command
+
+import (
+	"fmt"
+	"go/build"
+	"go/types"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"github.com/utisam/gogtok/query"
+)
+
+type layoutFieldRecord struct {
+	*query.FieldLayout
+}
+
+var layoutFieldColumns = []string{"name", "type", "offset", "size", "align", "padding"}
+
+func (r layoutFieldRecord) columnValue(name, key string) string {
+	switch name {
+	case "name":
+		return r.Name
+	case "type":
+		return types.TypeString(r.Type, nil)
+	case "offset":
+		return strconv.FormatInt(r.Offset, 10)
+	case "size":
+		return strconv.FormatInt(r.Size, 10)
+	case "align":
+		return strconv.FormatInt(r.Align, 10)
+	case "padding":
+		return strconv.FormatInt(r.Padding, 10)
+	}
+	return ""
+}
+
+type layoutSummary struct {
+	name        string
+	size        int64
+	align       int64
+	padding     int64
+	optimalSize int64
+}
+
+var layoutSummaryColumns = []string{"name", "size", "align", "padding", "optimal-size"}
+
+func (ls *layoutSummary) columnValue(name, key string) string {
+	switch name {
+	case "name":
+		return ls.name
+	case "size":
+		return strconv.FormatInt(ls.size, 10)
+	case "align":
+		return strconv.FormatInt(ls.align, 10)
+	case "padding":
+		return strconv.FormatInt(ls.padding, 10)
+	case "optimal-size":
+		return strconv.FormatInt(ls.optimalSize, 10)
+	}
+	return ""
+}
+
+func newLayout(c *config) *cobra.Command {
+	print0 := false
+	goarch := build.Default.GOARCH
+	compiler := "gc"
+	summary := false
+	optimize := false
+	maxPadding := int64(-1)
+	columns := []string{"name", "offset", "size", "padding"}
+
+	cmd := &cobra.Command{
+		Use:   "layout TYPE [package]",
+		Short: "Show memory layout of the struct",
+		Args:  cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			typeName := args[0]
+
+			sizes := types.SizesFor(compiler, goarch)
+			if sizes == nil {
+				return fmt.Errorf("unsupported compiler or architecture: %s/%s", compiler, goarch)
+			}
+
+			knownColumns := layoutFieldColumns
+			if summary {
+				knownColumns = layoutSummaryColumns
+				if !cmd.Flag("columns").Changed {
+					columns = []string{"name", "size", "align", "padding", "optimal-size"}
+				}
+			}
+
+			p := newRecordPrinter(print0)
+
+			cols, err := parseColumns(columns, knownColumns)
+			if err != nil {
+				return err
+			}
+
+			dir := "."
+			if len(args) > 1 {
+				dir = args[1]
+			}
+
+			pkgs, err := loadPackages(c.queryConfig(false), dir)
+			if err != nil {
+				return err
+			}
+
+			obj, ok := pkgs[0].Types.Scope().Lookup(typeName).(*types.TypeName)
+			if !ok {
+				return fmt.Errorf("type not found: %s", typeName)
+			}
+			st, ok := obj.Type().Underlying().(*types.Struct)
+			if !ok {
+				return fmt.Errorf("not a struct: %s", typeName)
+			}
+
+			vars := make([]*types.Var, st.NumFields())
+			for i := range vars {
+				vars[i] = st.Field(i)
+			}
+
+			fields, total := query.StructLayout(sizes, vars)
+			optimalFields, optimalTotal := query.StructLayout(sizes, query.OptimalFieldOrder(sizes, vars))
+
+			padding := int64(0)
+			for _, f := range fields {
+				padding += f.Padding
+			}
+
+			if summary {
+				p(recordValues(&layoutSummary{
+					name:        typeName,
+					size:        total,
+					align:       sizes.Alignof(st),
+					padding:     padding,
+					optimalSize: optimalTotal,
+				}, cols)...)
+			} else {
+				if optimize {
+					fields = optimalFields
+				}
+				for _, f := range fields {
+					p(recordValues(layoutFieldRecord{f}, cols)...)
+				}
+			}
+
+			if maxPadding >= 0 && padding > maxPadding {
+				return fmt.Errorf("%s has %d padding bytes (max %d)", typeName, padding, maxPadding)
+			}
+			return nil
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.BoolVarP(&print0, "print0", "0", print0, "Print info followed by a null character")
+	flags.StringVar(&goarch, "goarch", goarch, "Architecture to compute sizes")
+	flags.StringVar(&compiler, "compiler", compiler, "Compiler to compute sizes")
+	flags.BoolVar(&summary, "summary", summary, "Print the total size, alignment and padding of the struct")
+	flags.BoolVar(&optimize, "optimize", optimize, "Print fields in the suggested order minimizing padding")
+	flags.Int64Var(&maxPadding, "max-padding", maxPadding, "Fail if padding bytes exceed the value")
+	flags.StringSliceVar(&columns, "columns", columns, "Columns to be output (name, type, offset, size, align, padding; with --summary: name, size, align, padding, optimal-size)")
+
+	return cmd
+}
@@ -0,0 +1,52 @@
+package command
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLayout(t *testing.T) {
+	dir := newTestModule(t, map[string]string{
+		"a.go": `package test
+
+type T struct {
+	A bool
+	B int64
+	C int16
+}
+
+type N int
+`,
+	})
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"T"}, "A 0 1 7\nB 8 8 0\nC 16 2 6\n"},
+		{[]string{"T", "--optimize", "--columns", "name,type,align"}, "B int64 8\nC int16 2\nA bool 1\n"},
+		{[]string{"T", "--summary"}, "T 24 8 13 16\n"},
+		{[]string{"T", "--goarch", "386", "--summary", "--columns", "size"}, "16\n"},
+	}
+	for _, tt := range tests {
+		got, err := gogtokStdout(t, append(append([]string{"layout"}, tt.args...), dir)...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("layout %s = %q, want %q", strings.Join(tt.args, " "), got, tt.want)
+		}
+	}
+
+	for _, tt := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"T", "--max-padding", "12"}, "T has 13 padding bytes (max 12)"},
+		{[]string{"N"}, "not a struct: N"},
+		{[]string{"Missing"}, "type not found: Missing"},
+		{[]string{"T", "--goarch", "unknown"}, "unsupported compiler or architecture"},
+	} {
+		generateError(t, dir, tt.want, append([]string{"layout"}, tt.args...)...)
+	}
+}
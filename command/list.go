@@ -3,17 +3,15 @@ package command
 import (
 	"fmt"
 	"go/ast"
-	"go/parser"
 	"go/token"
-	"os"
-	"reflect"
 	"regexp"
 	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/utisam/gogtok/query"
 )
 
-func newList() *cobra.Command {
+func newList(c *config) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "list",
 		Short: "List declarations of the source file",
@@ -23,19 +21,26 @@ func newList() *cobra.Command {
 	cmd.AddCommand(newListValues())
 	cmd.AddCommand(newListTypes())
 	cmd.AddCommand(newListFields())
+	cmd.AddCommand(newListRefs(c))
+	cmd.AddCommand(newListCalls(c))
+	cmd.AddCommand(newListTests(c))
+	cmd.AddCommand(newListGenerate(c))
+	cmd.AddCommand(newListElements(c))
+	cmd.AddCommand(newListEmbeds(c))
+	cmd.AddCommand(newListParams())
+	cmd.AddCommand(newListResults())
 
 	return cmd
 }
 
 func inspectFiles(filenames []string, fn func(fset *token.FileSet, f *ast.File) error) error {
-	for _, filename := range filenames {
-		reader, err := os.Open(filename)
-		if err != nil {
-			return err
-		}
+	if err := recordInputs(filenames...); err != nil {
+		return err
+	}
 
+	for _, filename := range filenames {
 		fset := token.NewFileSet()
-		f, err := parser.ParseFile(fset, filename, reader, parser.Mode(0))
+		f, err := query.ParseFile(fset, filename)
 		if err != nil {
 			return err
 		}
@@ -71,21 +76,14 @@ func newListFuncs() *cobra.Command {
 				return err
 			}
 
-			inspectFiles(args, func(fset *token.FileSet, f *ast.File) error {
-				for _, decl := range f.Decls {
-					fnDecl, ok := decl.(*ast.FuncDecl)
-					if !ok {
-						continue
-					}
-
-					name := fnDecl.Name.Name
-					if matchPattern(patternRegexp, name) {
-						fmt.Println(name)
+			return inspectFiles(args, func(fset *token.FileSet, f *ast.File) error {
+				for _, fn := range query.Funcs(f) {
+					if matchPattern(patternRegexp, fn.Name) {
+						fmt.Println(fn.Name)
 					}
 				}
 				return nil
 			})
-			return nil
 		},
 	}
 
@@ -109,39 +107,17 @@ func newListValues() *cobra.Command {
 				return err
 			}
 
-			inspectFiles(args, func(fset *token.FileSet, f *ast.File) error {
-				for _, decl := range f.Decls {
-					genDecl, ok := decl.(*ast.GenDecl)
-					if !ok || (genDecl.Tok != token.VAR && genDecl.Tok != token.CONST) {
+			return inspectFiles(args, func(fset *token.FileSet, f *ast.File) error {
+				for _, value := range query.Values(f) {
+					if filterDeclType != "" && value.Type != filterDeclType {
 						continue
 					}
-
-					declType := ""
-					for _, spec := range genDecl.Specs {
-						valSpec, ok := spec.(*ast.ValueSpec)
-						if !ok {
-							continue
-						}
-
-						if valSpec.Type != nil {
-							declType = typeString(valSpec.Type)
-						}
-
-						if filterDeclType != "" && declType != filterDeclType {
-							continue
-						}
-
-						for _, nameIdent := range valSpec.Names {
-							name := nameIdent.Name
-							if matchPattern(patternRegexp, name) {
-								fmt.Println(name)
-							}
-						}
+					if matchPattern(patternRegexp, value.Name) {
+						fmt.Println(value.Name)
 					}
 				}
 				return nil
 			})
-			return nil
 		},
 	}
 
@@ -165,28 +141,14 @@ func newListTypes() *cobra.Command {
 				return err
 			}
 
-			inspectFiles(args, func(fset *token.FileSet, f *ast.File) error {
-				for _, decl := range f.Decls {
-					genDecl, ok := decl.(*ast.GenDecl)
-					if !ok || genDecl.Tok != token.TYPE {
-						continue
-					}
-
-					for _, spec := range genDecl.Specs {
-						typeSpec, ok := spec.(*ast.TypeSpec)
-						if !ok {
-							continue
-						}
-
-						name := typeSpec.Name.Name
-						if matchPattern(patternRegexp, name) {
-							fmt.Println(name)
-						}
+			return inspectFiles(args, func(fset *token.FileSet, f *ast.File) error {
+				for _, typeDecl := range query.Types(f) {
+					if matchPattern(patternRegexp, typeDecl.Name) {
+						fmt.Println(typeDecl.Name)
 					}
 				}
 				return nil
 			})
-			return nil
 		},
 	}
 
@@ -196,31 +158,22 @@ func newListTypes() *cobra.Command {
 	return cmd
 }
 
-type fieldInfo struct {
-	name      string
-	fieldType string
-	tag       string
+// record is a row of list commands.
+type record interface {
+	// columnValue returns a value of the column.
+	// key is specified by a column such as "tag[key]".
+	columnValue(name, key string) string
 }
 
-func newFieldInfo(name string, typeExpr ast.Expr, tagLit *ast.BasicLit) *fieldInfo {
-	tag := ""
-	if tagLit != nil {
-		tag = strings.Trim(tagLit.Value, "`")
-	}
-
-	return &fieldInfo{
-		name:      name,
-		fieldType: typeString(typeExpr),
-		tag:       tag,
-	}
-}
+type recordColumn func(record) string
 
-type fieldInfoColumn func(*fieldInfo) string
+var columnPattern = regexp.MustCompile(`^([\w-]+)(?:\[([^\]]+)\])?$`)
 
-func parseColumns(ss []string) ([]fieldInfoColumn, error) {
-	cols := make([]fieldInfoColumn, len(ss))
+// parseColumns parses columns with known column names such as "name" or "tag[key]".
+func parseColumns(ss []string, known []string) ([]recordColumn, error) {
+	cols := make([]recordColumn, len(ss))
 	for i, s := range ss {
-		col, err := parseColumn(s)
+		col, err := parseColumn(s, known)
 		if err != nil {
 			return nil, err
 		}
@@ -229,72 +182,73 @@ func parseColumns(ss []string) ([]fieldInfoColumn, error) {
 	return cols, nil
 }
 
-var tagColumnPattern = regexp.MustCompile(`tag\[([^\]]+)\]`)
+func parseColumn(s string, known []string) (recordColumn, error) {
+	submatch := columnPattern.FindStringSubmatch(s)
+	if submatch == nil {
+		return nil, fmt.Errorf("Unknown column: %s", s)
+	}
 
-func parseColumn(s string) (fieldInfoColumn, error) {
-	switch s {
-	case "name":
-		return func(fi *fieldInfo) string {
-			return fi.name
-		}, nil
-	case "type":
-		return func(fi *fieldInfo) string {
-			return fi.fieldType
-		}, nil
-	case "tags":
-		return func(fi *fieldInfo) string {
-			return fi.tag
-		}, nil
+	name, key := submatch[1], submatch[2]
+	spec := name
+	if key != "" {
+		spec = name + "[key]"
 	}
 
-	submatch := tagColumnPattern.FindStringSubmatch(s)
-	if submatch != nil {
-		return func(fi *fieldInfo) string {
-			return reflect.StructTag(fi.tag).Get(submatch[1])
-		}, nil
+	for _, k := range known {
+		if k == spec {
+			return func(r record) string {
+				return r.columnValue(name, key)
+			}, nil
+		}
 	}
 
 	return nil, fmt.Errorf("Unknown column: %s", s)
 }
 
-func (fi *fieldInfo) toValues(cols []fieldInfoColumn) []string {
+func recordValues(r record, cols []recordColumn) []string {
 	res := make([]string, len(cols))
 	for i, col := range cols {
-		res[i] = col(fi)
+		res[i] = col(r)
 	}
 	return res
 }
 
-func defaultFieldPrinter(a ...string) {
+type recordPrinter func(a ...string)
+
+func newRecordPrinter(print0 bool) recordPrinter {
+	if print0 {
+		return nullCharPrinter
+	}
+	return defaultPrinter
+}
+
+func defaultPrinter(a ...string) {
 	fmt.Println(strings.Join(a, " "))
 }
 
-func nullCharFieldPrinter(a ...string) {
+func nullCharPrinter(a ...string) {
 	fmt.Print(strings.Join(a, "\x00"))
 	fmt.Print("\x00")
 }
 
-func getFieldsOfType(spec ast.Spec, typeName string) *ast.FieldList {
-	if typeSpec, ok := spec.(*ast.TypeSpec); ok && typeName == typeSpec.Name.Name {
-		switch specType := typeSpec.Type.(type) {
-		case *ast.StructType:
-			return specType.Fields
-		case *ast.InterfaceType:
-			return specType.Methods
-		}
-	}
-	return nil
+type fieldRecord struct {
+	*query.Field
 }
 
-func forEachFlattenField(fields *ast.FieldList, fn func(name string, fieldType ast.Expr, fieldTag *ast.BasicLit)) {
-	if fields == nil {
-		return
-	}
-	for _, field := range fields.List {
-		for _, nameIdent := range field.Names {
-			fn(nameIdent.Name, field.Type, field.Tag)
-		}
+var fieldColumns = []string{"name", "type", "tags", "tag[key]"}
+
+func (r fieldRecord) columnValue(name, key string) string {
+	switch name {
+	case "name":
+		return r.Name
+	case "type":
+		return r.Type
+	case "tags":
+		return r.Tag
+	case "tag":
+		return r.TagValue(key)
 	}
+	return ""
 }
 
 func newListFields() *cobra.Command {
@@ -314,38 +268,21 @@ func newListFields() *cobra.Command {
 				return err
 			}
 
-			p := defaultFieldPrinter
-			if print0 {
-				p = nullCharFieldPrinter
-			}
+			p := newRecordPrinter(print0)
 
-			cols, err := parseColumns(columns)
+			cols, err := parseColumns(columns, fieldColumns)
 			if err != nil {
 				return err
 			}
 
-			inspectFiles([]string{filename}, func(fset *token.FileSet, f *ast.File) error {
-				for _, decl := range f.Decls {
-					genDecl, ok := decl.(*ast.GenDecl)
-					if !ok || genDecl.Tok != token.TYPE {
-						continue
-					}
-
-					for _, spec := range genDecl.Specs {
-						forEachFlattenField(
-							getFieldsOfType(spec, typeName),
-							func(name string, fieldType ast.Expr, fieldTag *ast.BasicLit) {
-								if matchPattern(patternRegexp, name) {
-									info := newFieldInfo(name, fieldType, fieldTag)
-									p(info.toValues(cols)...)
-								}
-							},
-						)
+			return inspectFiles([]string{filename}, func(fset *token.FileSet, f *ast.File) error {
+				for _, field := range query.Fields(f, typeName) {
+					if matchPattern(patternRegexp, field.Name) {
+						p(recordValues(fieldRecord{field}, cols)...)
 					}
 				}
 				return nil
 			})
-			return nil
 		},
 	}
 
@@ -356,102 +293,3 @@ func newListFields() *cobra.Command {
 
 	return cmd
 }
-
-func typeString(expr ast.Expr) string {
-	b := &strings.Builder{}
-	b.Grow(int(expr.End()) - int(expr.Pos()))
-	appendExpr(b, expr)
-	return b.String()
-}
-
-func appendExpr(b *strings.Builder, expr ast.Expr) {
-	switch t := expr.(type) {
-	case nil:
-	case *ast.Ident:
-		b.WriteString(t.Name)
-	case *ast.SelectorExpr:
-		appendExpr(b, t.X)
-		b.WriteRune('.')
-		appendExpr(b, t.Sel)
-	case *ast.StarExpr:
-		b.WriteRune('*')
-		appendExpr(b, t.X)
-	case *ast.ArrayType:
-		b.WriteRune('[')
-		appendExpr(b, t.Len)
-		b.WriteRune(']')
-		appendExpr(b, t.Elt)
-	case *ast.StructType:
-		// TODO: Fields
-		b.WriteString("struct{}")
-	case *ast.FuncType:
-		appendFuncType(b, t)
-	case *ast.InterfaceType:
-		// TODO: Fields
-		b.WriteString("interface{}")
-	case *ast.MapType:
-		b.WriteString("map[")
-		appendExpr(b, t.Key)
-		b.WriteString("]")
-		appendExpr(b, t.Value)
-	case *ast.ChanType:
-		appendChanType(b, t)
-	case *ast.BasicLit:
-		b.WriteString(t.Value)
-	case *ast.Ellipsis:
-		b.WriteString("...")
-		appendExpr(b, t.Elt)
-	default:
-		panic(fmt.Sprintf("unsupported type: %#v", expr))
-	}
-}
-
-func appendFuncType(b *strings.Builder, t *ast.FuncType) {
-	numParams := t.Params.NumFields()
-	if numParams == 0 {
-		b.WriteString("func()")
-	} else {
-		b.WriteString("func(")
-		appendFieldList(b, t.Params)
-		b.WriteRune(')')
-	}
-	numResults := t.Results.NumFields()
-	if numResults == 1 {
-		b.WriteRune(' ')
-		appendFieldList(b, t.Results)
-	} else if numResults >= 2 {
-		b.WriteString(" (")
-		appendFieldList(b, t.Results)
-		b.WriteRune(')')
-	}
-}
-
-func appendChanType(b *strings.Builder, t *ast.ChanType) {
-	if t.Dir == ast.RECV {
-		b.WriteString("<-")
-	}
-	b.WriteString("chan")
-	if t.Dir == ast.SEND {
-		b.WriteString("<-")
-	}
-	b.WriteRune(' ')
-	appendExpr(b, t.Value)
-}
-
-func appendFieldList(b *strings.Builder, expr *ast.FieldList) {
-	for i, field := range expr.List {
-		if i != 0 {
-			b.WriteString(", ")
-		}
-		if len(field.Names) > 0 {
-			for j, name := range field.Names {
-				if j != 0 {
-					b.WriteString(", ")
-				}
-				b.WriteString(name.Name)
-			}
-			b.WriteRune(' ')
-		}
-		appendExpr(b, field.Type)
-	}
-}
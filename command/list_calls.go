@@ -0,0 +1,101 @@
+package command
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/utisam/gogtok/query"
+)
+
+type callRecord struct {
+	*query.Call
+}
+
+var callColumns = []string{"pos", "file", "line", "package", "func", "args", "nargs", "arg[key]", "const[key]"}
+
+func (r callRecord) columnValue(name, key string) string {
+	switch name {
+	case "pos":
+		return r.Pos.String()
+	case "file":
+		return r.Pos.Filename
+	case "line":
+		return strconv.Itoa(r.Pos.Line)
+	case "package":
+		return r.Package
+	case "func":
+		return r.Func
+	case "args":
+		return strings.Join(r.Args, ", ")
+	case "nargs":
+		return strconv.Itoa(len(r.Args))
+	case "arg":
+		return indexedValue(r.Args, key)
+	case "const":
+		return indexedValue(r.Consts, key)
+	}
+	return ""
+}
+
+func indexedValue(values []string, key string) string {
+	i, err := strconv.Atoi(key)
+	if err != nil || i < 0 || i >= len(values) {
+		return ""
+	}
+	return values[i]
+}
+
+func newListCalls(c *config) *cobra.Command {
+	print0 := false
+	tests := false
+	columns := []string{"pos", "args"}
+
+	cmd := &cobra.Command{
+		Use:   "calls FUNC [packages...]",
+		Short: "List calls of the function",
+		Long: `List calls of the function.
+
+FUNC is one of "Func", "pkg.Func", "Type.Method" or "path/to/pkg.Type.Method".
+"arg[N]" column is the source of the N-th argument and "const[N]" is its constant value.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			objectQuery := query.ParseObjectQuery(args[0])
+
+			p := newRecordPrinter(print0)
+
+			cols, err := parseColumns(columns, callColumns)
+			if err != nil {
+				return err
+			}
+
+			dirs, err := query.ExpandPatterns(args[1:])
+			if err != nil {
+				return err
+			}
+
+			pkgs, err := loadPackages(c.queryConfig(tests), dirs...)
+			if err != nil {
+				return err
+			}
+
+			targets := objectQuery.Resolve(pkgs)
+			if len(targets) == 0 {
+				return fmt.Errorf("no object found: %s", args[0])
+			}
+
+			for _, call := range query.Calls(pkgs, targets) {
+				p(recordValues(callRecord{call}, cols)...)
+			}
+			return nil
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.BoolVarP(&print0, "print0", "0", print0, "Print info followed by a null character")
+	flags.BoolVar(&tests, "tests", tests, "Include test files of the packages")
+	flags.StringSliceVar(&columns, "columns", columns, "Columns to be output (pos, file, line, package, func, args, nargs, arg[N], const[N])")
+
+	return cmd
+}
@@ -0,0 +1,71 @@
+package command
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestListCalls(t *testing.T) {
+	dir := newTestModule(t, map[string]string{
+		"log/log.go": `package log
+
+type Logger struct{}
+
+func (l *Logger) Printf(format string, args ...interface{}) {}
+
+func Map[T, U any](v T, f func(T) U) U { return f(v) }
+`,
+		"a.go": `package test
+
+import "example.com/test/log"
+
+const name = "a"
+
+type Server struct {
+	logger *log.Logger
+}
+
+func (s *Server) Start(port int) {
+	s.logger.Printf("start %s", name+"!")
+	(s.logger.Printf)("port %d", port)
+}
+
+func Convert() string {
+	return log.Map[int, string](1, func(int) string { return "" })
+}
+`,
+		"a_test.go": `package test
+
+import "example.com/test/log"
+
+func helper() {
+	new(log.Logger).Printf("test")
+}
+`,
+	})
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"log.Logger.Printf", "--columns", "func,nargs,args"}, "Server.Start 2 \"start %s\", name + \"!\"\nServer.Start 2 \"port %d\", port\n"},
+		{[]string{"example.com/test/log.Logger.Printf", "--columns", "line,const[0],const[1],arg[2]"}, "12 start %s a! \n13 port %d  \n"},
+		{[]string{"log.Logger.Printf", "--tests", "--columns", "func,nargs"}, "Server.Start 2\nServer.Start 2\nhelper 1\n"},
+		{[]string{"log.Map", "--columns", "package,func,arg[0]"}, "example.com/test Convert 1\n"},
+	}
+	for _, tt := range tests {
+		args := append(append([]string{"list", "calls"}, tt.args...), dir, dir+"/log")
+		got, err := gogtokStdout(t, args...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("list calls %s = %q, want %q", strings.Join(tt.args, " "), got, tt.want)
+		}
+	}
+
+	err := runGogtok("list", "calls", "Missing", dir)
+	if err == nil || !strings.Contains(err.Error(), "no object found: Missing") {
+		t.Errorf("missing functions must be reported: %v", err)
+	}
+}
@@ -0,0 +1,92 @@
+package command
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"github.com/utisam/gogtok/query"
+)
+
+type elementRecord struct {
+	*query.Element
+}
+
+var elementColumns = []string{"path", "index", "key", "key-const", "value", "const", "pos", "line"}
+
+func (r elementRecord) columnValue(name, key string) string {
+	switch name {
+	case "path":
+		return r.Path
+	case "index":
+		return strconv.Itoa(r.Index)
+	case "key":
+		return r.Key
+	case "key-const":
+		return r.KeyConst
+	case "value":
+		return r.Value
+	case "const":
+		return r.Const
+	case "pos":
+		return r.Pos.String()
+	case "line":
+		return strconv.Itoa(r.Pos.Line)
+	}
+	return ""
+}
+
+func newListElements(c *config) *cobra.Command {
+	print0 := false
+	nested := false
+	columns := []string{"key", "value"}
+
+	cmd := &cobra.Command{
+		Use:   "elements FILE VAR",
+		Short: "List elements of the composite literal initializing the variable",
+		Long: `List elements of the composite literal initializing the variable.
+
+"const" and "key-const" columns are values of constant expressions. Strings are unquoted.
+"path" column is like "[0]", "[\"key\"]" or "[\"key\"].Field" for nested elements.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(_ *cobra.Command, args []string) error {
+			filename, varName := args[0], args[1]
+
+			p := newRecordPrinter(print0)
+
+			cols, err := parseColumns(columns, elementColumns)
+			if err != nil {
+				return err
+			}
+
+			pkgs, err := loadPackages(c.queryConfig(true), filepath.Dir(filename))
+			if err != nil {
+				return err
+			}
+			pkg := pkgs[0]
+
+			f := pkg.File(filename)
+			if f == nil {
+				return fmt.Errorf("file is not in the package: %s", filename)
+			}
+
+			elements, err := query.Elements(pkg, f, varName, nested)
+			if err != nil {
+				return err
+			}
+
+			for _, element := range elements {
+				p(recordValues(elementRecord{element}, cols)...)
+			}
+			return nil
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.BoolVarP(&print0, "print0", "0", print0, "Print info followed by a null character")
+	flags.BoolVar(&nested, "nested", nested, "Print elements of nested composite literals")
+	flags.StringSliceVar(&columns, "columns", columns, "Columns to be output (path, index, key, key-const, value, const, pos, line)")
+
+	return cmd
+}
@@ -0,0 +1,44 @@
+package command
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestListElements(t *testing.T) {
+	dir := newTestModule(t, map[string]string{
+		"a.go": `package test
+
+var names = map[int]string{1: "one", 2: "two"}
+`,
+		"a_test.go": `package test
+
+var cases = []struct{ in, out string }{{"a", "A"}}
+`,
+	})
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"a.go", "names"}, "1 \"one\"\n2 \"two\"\n"},
+		{[]string{"a.go", "names", "--columns", "line,index,key-const,const"}, "3 0 1 one\n3 1 2 two\n"},
+		{[]string{"a_test.go", "cases", "--nested", "--columns", "path,const"}, "[0] \n[0][0] a\n[0][1] A\n"},
+	}
+	for _, tt := range tests {
+		args := append([]string{"list", "elements", filepath.Join(dir, tt.args[0])}, tt.args[1:]...)
+		got, err := gogtokStdout(t, args...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("list elements %s = %q, want %q", strings.Join(tt.args, " "), got, tt.want)
+		}
+	}
+
+	err := runGogtok("list", "elements", filepath.Join(dir, "a.go"), "cases")
+	if err == nil || !strings.Contains(err.Error(), "composite literal not found: cases") {
+		t.Errorf("variables in other files must not be found: %v", err)
+	}
+}
@@ -0,0 +1,125 @@
+package command
+
+import (
+	"fmt"
+	"go/token"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/utisam/gogtok/query"
+)
+
+type embedRecord struct {
+	*query.Embed
+}
+
+var embedColumns = []string{"pos", "file", "line", "var", "type", "pattern", "files", "count", "status"}
+
+func (r embedRecord) columnValue(name, key string) string {
+	switch name {
+	case "pos":
+		return r.Pos.String()
+	case "file":
+		return r.Pos.Filename
+	case "line":
+		return strconv.Itoa(r.Pos.Line)
+	case "var":
+		return r.Var
+	case "type":
+		return r.Type
+	case "pattern":
+		return r.Pattern
+	case "files":
+		return strings.Join(r.Files, ",")
+	case "count":
+		return strconv.Itoa(len(r.Files))
+	case "status":
+		return r.Status
+	}
+	return ""
+}
+
+func newListEmbeds(c *config) *cobra.Command {
+	print0 := false
+	strict := false
+	columns := []string{"pos", "var", "pattern", "files"}
+
+	cmd := &cobra.Command{
+		Use:   "embeds [packages...]",
+		Short: "List go:embed directives of the packages and files matched with them",
+		RunE: func(_ *cobra.Command, args []string) error {
+			p := newRecordPrinter(print0)
+
+			cols, err := parseColumns(columns, embedColumns)
+			if err != nil {
+				return err
+			}
+
+			dirs, err := query.ExpandPatterns(args)
+			if err != nil {
+				return err
+			}
+
+			numErrors := 0
+			for _, dir := range dirs {
+				buildPkg, err := c.buildContext().ImportDir(dir, 0)
+				if err != nil {
+					return err
+				}
+
+				filenames := []string{}
+				for _, files := range [][]string{buildPkg.GoFiles, buildPkg.TestGoFiles, buildPkg.XTestGoFiles} {
+					filenames = append(filenames, files...)
+				}
+
+				for _, filename := range filenames {
+					filename = filepath.Join(dir, filename)
+					if err := recordInputs(filename); err != nil {
+						return err
+					}
+
+					fset := token.NewFileSet()
+					f, err := query.ParseFile(fset, filename)
+					if err != nil {
+						return err
+					}
+
+					embeds, err := query.Embeds(dir, fset, f)
+					if err != nil {
+						return err
+					}
+
+					for _, embed := range embeds {
+						for _, file := range embed.Files {
+							if err := recordInputs(filepath.Join(dir, file)); err != nil {
+								return err
+							}
+						}
+
+						if embed.Status != "ok" {
+							numErrors++
+							logrus.WithField("pos", embed.Pos).WithField("pattern", embed.Pattern).Warnf("Pattern is %s", embed.Status)
+						}
+
+						p(recordValues(embedRecord{embed}, cols)...)
+					}
+				}
+			}
+
+			if strict && numErrors > 0 {
+				return fmt.Errorf("%d patterns are invalid or match no files", numErrors)
+			}
+			return nil
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.BoolVarP(&print0, "print0", "0", print0, "Print info followed by a null character")
+	flags.BoolVar(&strict, "strict", strict, "Fail if any pattern is invalid or matches no files")
+	flags.StringSliceVar(&columns, "columns", columns, "Columns to be output (pos, file, line, var, type, pattern, files, count, status)")
+
+	return cmd
+}
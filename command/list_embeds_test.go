@@ -0,0 +1,32 @@
+package command
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestListEmbeds(t *testing.T) {
+	dir := newTestModule(t, map[string]string{
+		"a.go": `package test
+
+import _ "embed"
+
+//go:embed a.txt b.txt
+var text string
+`,
+		"a.txt": "a",
+	})
+
+	got, err := gogtokStdout(t, "list", "embeds", "--columns", "line,var,type,pattern,count,status", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "5 text string a.txt 1 multiple\n5 text string b.txt 0 nomatch\n"; got != want {
+		t.Errorf("list embeds = %q, want %q", got, want)
+	}
+
+	_, err = gogtokStdout(t, "list", "embeds", "--strict", dir)
+	if err == nil || !strings.Contains(err.Error(), "2 patterns are invalid or match no files") {
+		t.Errorf("invalid patterns must be reported with --strict: %v", err)
+	}
+}
@@ -0,0 +1,93 @@
+package command
+
+import (
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"github.com/utisam/gogtok/query"
+)
+
+type generateRecord struct {
+	*query.GenerateDirective
+}
+
+var generateColumns = []string{"file", "line", "pos", "dir", "package", "command", "script", "gogtok"}
+
+func (r generateRecord) columnValue(name, key string) string {
+	switch name {
+	case "file":
+		return r.File
+	case "line":
+		return strconv.Itoa(r.Line)
+	case "pos":
+		return r.File + ":" + strconv.Itoa(r.Line)
+	case "dir":
+		return r.Dir
+	case "package":
+		return r.Package
+	case "command":
+		return r.Command
+	case "script":
+		return r.Script
+	case "gogtok":
+		return strconv.FormatBool(r.Gogtok)
+	}
+	return ""
+}
+
+func newListGenerate(c *config) *cobra.Command {
+	pattern := ""
+	print0 := false
+	gogtokOnly := false
+	columns := []string{"pos", "command"}
+
+	cmd := &cobra.Command{
+		Use:   "generate [packages...]",
+		Short: "List go:generate directives of the packages",
+		RunE: func(_ *cobra.Command, args []string) error {
+			patternRegexp, err := compilePattern(pattern)
+			if err != nil {
+				return err
+			}
+
+			p := newRecordPrinter(print0)
+
+			cols, err := parseColumns(columns, generateColumns)
+			if err != nil {
+				return err
+			}
+
+			dirs, err := query.ExpandPatterns(args)
+			if err != nil {
+				return err
+			}
+
+			if err := recordDirs(dirs...); err != nil {
+				return err
+			}
+
+			directives, err := query.GenerateDirectives(c.queryConfig(false), dirs...)
+			if err != nil {
+				return err
+			}
+
+			for _, gd := range directives {
+				if gogtokOnly && !gd.Gogtok {
+					continue
+				}
+				if matchPattern(patternRegexp, gd.Command) {
+					p(recordValues(generateRecord{gd}, cols)...)
+				}
+			}
+			return nil
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVarP(&pattern, "pattern", "p", pattern, "Only print commands matching the pattern")
+	flags.BoolVarP(&print0, "print0", "0", print0, "Print info followed by a null character")
+	flags.BoolVar(&gogtokOnly, "gogtok", gogtokOnly, "Only print directives invoking scripts created by 'gogtok new'")
+	flags.StringSliceVar(&columns, "columns", columns, "Columns to be output (file, line, pos, dir, package, command, script, gogtok)")
+
+	return cmd
+}
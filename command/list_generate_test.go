@@ -0,0 +1,43 @@
+package command
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestListGenerate(t *testing.T) {
+	dir := newTestModule(t, map[string]string{
+		"a.go":      "package test\n\n//go:generate ./gen.sh\n//go:generate stringer -type=Kind\n",
+		"sub/b.go":  "package sub\n\n//go:generate go run ./cmd\n",
+		"gen.sh":    "#!/bin/bash\necho \"// Code generated by './gen.sh'. DO NOT EDIT.\"\n",
+		"sub/c.txt": "//go:generate ignored\n",
+	})
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"./..."}, "a.go:3 ./gen.sh\na.go:4 stringer -type=Kind\nsub/b.go:3 go run ./cmd\n"},
+		{[]string{"--gogtok", "--columns", "line,script,gogtok"}, "3 gen.sh true\n"},
+		{[]string{"-p", "^stringer", "--columns", "package,command"}, "test stringer -type=Kind\n"},
+		{[]string{"-0", "--columns", "file,line", "sub"}, "sub/b.go\x003\x00"},
+	}
+	for _, tt := range tests {
+		got, err := gogtokStdout(t, append([]string{"list", "generate"}, tt.args...)...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != filepath.FromSlash(tt.want) {
+			t.Errorf("list generate %v = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+}
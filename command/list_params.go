@@ -0,0 +1,100 @@
+package command
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"github.com/utisam/gogtok/query"
+)
+
+type paramRecord struct {
+	*query.Param
+}
+
+var paramColumns = []string{"name", "type", "index", "variadic", "ident", "arg"}
+
+func (r paramRecord) columnValue(name, key string) string {
+	switch name {
+	case "name":
+		return r.Name
+	case "type":
+		return r.Type
+	case "index":
+		return strconv.Itoa(r.Index)
+	case "variadic":
+		return strconv.FormatBool(r.Variadic)
+	case "ident":
+		return r.Ident
+	case "arg":
+		return r.Arg()
+	}
+	return ""
+}
+
+func newListParams() *cobra.Command {
+	return newListSignature("params", "List parameters of the function", "p", func(t *ast.FuncType) *ast.FieldList {
+		return t.Params
+	})
+}
+
+func newListResults() *cobra.Command {
+	return newListSignature("results", "List results of the function", "r", func(t *ast.FuncType) *ast.FieldList {
+		return t.Results
+	})
+}
+
+func newListSignature(use, short, prefix string, getFields func(*ast.FuncType) *ast.FieldList) *cobra.Command {
+	print0 := false
+	columns := []string{"ident", "type"}
+
+	cmd := &cobra.Command{
+		Use:   use + " FILE FUNC",
+		Short: short,
+		Long: short + `.
+
+FUNC is one of "Func", "Type.Method", "Interface.Method" or "FuncType".
+"ident" column is the name or "` + prefix + `INDEX" if it is unnamed.
+Underscores are appended to "` + prefix + `INDEX" if the signature or the receiver already uses the name.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(_ *cobra.Command, args []string) error {
+			filename, funcName := args[0], args[1]
+
+			p := newRecordPrinter(print0)
+
+			cols, err := parseColumns(columns, paramColumns)
+			if err != nil {
+				return err
+			}
+
+			found := false
+			err = inspectFiles([]string{filename}, func(fset *token.FileSet, f *ast.File) error {
+				fnType, recv := query.FindFunc(f, funcName)
+				if fnType == nil {
+					return nil
+				}
+				found = true
+
+				for _, param := range query.Params(getFields(fnType), prefix, fnType.Params, fnType.Results, recv) {
+					p(recordValues(paramRecord{param}, cols)...)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			if !found {
+				return fmt.Errorf("function not found: %s", funcName)
+			}
+			return nil
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.BoolVarP(&print0, "print0", "0", print0, "Print info followed by a null character")
+	flags.StringSliceVar(&columns, "columns", columns, "Columns to be output (name, type, index, variadic, ident, arg)")
+
+	return cmd
+}
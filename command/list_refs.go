@@ -0,0 +1,90 @@
+package command
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"github.com/utisam/gogtok/query"
+)
+
+type refRecord struct {
+	*query.Ref
+}
+
+var refColumns = []string{"pos", "file", "line", "column", "name", "package", "func", "kind"}
+
+func (r refRecord) columnValue(name, key string) string {
+	switch name {
+	case "pos":
+		return r.Pos.String()
+	case "file":
+		return r.Pos.Filename
+	case "line":
+		return strconv.Itoa(r.Pos.Line)
+	case "column":
+		return strconv.Itoa(r.Pos.Column)
+	case "name":
+		return r.Name
+	case "package":
+		return r.Package
+	case "func":
+		return r.Func
+	case "kind":
+		return r.Kind
+	}
+	return ""
+}
+
+func newListRefs(c *config) *cobra.Command {
+	print0 := false
+	tests := false
+	columns := []string{"pos", "kind", "func"}
+
+	cmd := &cobra.Command{
+		Use:   "refs NAME [packages...]",
+		Short: "List references to the named object",
+		Long: `List references to the named object.
+
+NAME is one of "Name", "pkg.Name", "Type.Member" or "path/to/pkg.Type.Member".
+Packages are directories such as "." or "./...".`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			objectQuery := query.ParseObjectQuery(args[0])
+
+			p := newRecordPrinter(print0)
+
+			cols, err := parseColumns(columns, refColumns)
+			if err != nil {
+				return err
+			}
+
+			dirs, err := query.ExpandPatterns(args[1:])
+			if err != nil {
+				return err
+			}
+
+			pkgs, err := loadPackages(c.queryConfig(tests), dirs...)
+			if err != nil {
+				return err
+			}
+
+			targets := objectQuery.Resolve(pkgs)
+			if len(targets) == 0 {
+				return fmt.Errorf("no object found: %s", args[0])
+			}
+
+			for _, ref := range query.Refs(pkgs, targets) {
+				p(recordValues(refRecord{ref}, cols)...)
+			}
+			return nil
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.BoolVarP(&print0, "print0", "0", print0, "Print info followed by a null character")
+	flags.BoolVar(&tests, "tests", tests, "Include test files of the packages")
+	flags.StringSliceVar(&columns, "columns", columns, "Columns to be output (pos, file, line, column, name, package, func, kind)")
+
+	return cmd
+}
@@ -0,0 +1,111 @@
+package command
+
+import (
+	"go/token"
+	"path/filepath"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"github.com/utisam/gogtok/query"
+)
+
+type testRecord struct {
+	*query.Test
+}
+
+var testColumns = []string{"name", "kind", "package", "pos", "file", "line", "valid", "error"}
+
+func (r testRecord) columnValue(name, key string) string {
+	switch name {
+	case "name":
+		return r.Name
+	case "kind":
+		return r.Kind
+	case "package":
+		return r.Package
+	case "pos":
+		return r.Pos.String()
+	case "file":
+		return r.Pos.Filename
+	case "line":
+		return strconv.Itoa(r.Pos.Line)
+	case "valid":
+		return strconv.FormatBool(r.Valid())
+	case "error":
+		return r.Err
+	}
+	return ""
+}
+
+func newListTests(c *config) *cobra.Command {
+	pattern := ""
+	print0 := false
+	subtests := true
+	kinds := []string{}
+	columns := []string{"kind", "name"}
+
+	cmd := &cobra.Command{
+		Use:   "tests [packages...]",
+		Short: "List test functions of the packages",
+		RunE: func(_ *cobra.Command, args []string) error {
+			patternRegexp, err := compilePattern(pattern)
+			if err != nil {
+				return err
+			}
+
+			p := newRecordPrinter(print0)
+
+			cols, err := parseColumns(columns, testColumns)
+			if err != nil {
+				return err
+			}
+
+			kindFilter := map[string]bool{}
+			for _, kind := range kinds {
+				kindFilter[kind] = true
+			}
+
+			dirs, err := query.ExpandPatterns(args)
+			if err != nil {
+				return err
+			}
+
+			for _, dir := range dirs {
+				buildPkg, err := c.buildContext().ImportDir(dir, 0)
+				if err != nil {
+					return err
+				}
+
+				filenames := append(append([]string{}, buildPkg.TestGoFiles...), buildPkg.XTestGoFiles...)
+				for _, filename := range filenames {
+					filename = filepath.Join(dir, filename)
+					if err := recordInputs(filename); err != nil {
+						return err
+					}
+
+					fset := token.NewFileSet()
+					f, err := query.ParseFile(fset, filename)
+					if err != nil {
+						return err
+					}
+
+					for _, test := range query.Tests(fset, f, subtests) {
+						if (len(kindFilter) == 0 || kindFilter[test.Kind]) && matchPattern(patternRegexp, test.Name) {
+							p(recordValues(testRecord{test}, cols)...)
+						}
+					}
+				}
+			}
+			return nil
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVarP(&pattern, "pattern", "p", pattern, "Only print names matching the pattern")
+	flags.BoolVarP(&print0, "print0", "0", print0, "Print info followed by a null character")
+	flags.BoolVar(&subtests, "subtests", subtests, "Print subtests run with literal names")
+	flags.StringSliceVar(&kinds, "kinds", kinds, "Only print the kinds (main, test, benchmark, fuzz, example, subtest)")
+	flags.StringSliceVar(&columns, "columns", columns, "Columns to be output (name, kind, package, pos, file, line, valid, error)")
+
+	return cmd
+}
@@ -0,0 +1,243 @@
+package command
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"go/build"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/utisam/gogtok/query"
+)
+
+// manifestEnv is the environment variable of the manifest file.
+// If it is set, commands append paths of files they read to the manifest.
+const manifestEnv = "GOGTOK_MANIFEST"
+
+// recordInputs appends the files to the manifest.
+func recordInputs(filenames ...string) error {
+	manifest := os.Getenv(manifestEnv)
+	if manifest == "" || len(filenames) == 0 {
+		return nil
+	}
+
+	b := &strings.Builder{}
+	for _, filename := range filenames {
+		abs, err := filepath.Abs(filename)
+		if err != nil {
+			return err
+		}
+		b.WriteString(abs)
+		b.WriteByte('\n')
+	}
+
+	f, err := os.OpenFile(manifest, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(f, b.String()); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// recordDirs appends Go files in the directories to the manifest.
+func recordDirs(dirs ...string) error {
+	if os.Getenv(manifestEnv) == "" {
+		return nil
+	}
+
+	filenames := []string{}
+	for _, dir := range dirs {
+		matches, err := filepath.Glob(filepath.Join(dir, "*.go"))
+		if err != nil {
+			return err
+		}
+		filenames = append(filenames, matches...)
+	}
+	return recordInputs(filenames...)
+}
+
+// recordGoMod appends go.mod of the module containing the directory to the manifest.
+func recordGoMod(dir string) error {
+	if os.Getenv(manifestEnv) == "" {
+		return nil
+	}
+
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	goMod, err := query.FindGoModFile(absDir)
+	if err != nil {
+		return nil
+	}
+	return recordInputs(goMod)
+}
+
+// loadPackages loads the packages and appends their files to the manifest.
+// Files of imported packages out of GOROOT are also appended because they affect types.
+func loadPackages(cfg *query.LoadConfig, dirs ...string) ([]*query.Package, error) {
+	pkgs, err := query.Load(cfg, dirs...)
+	if err != nil {
+		return nil, err
+	}
+	if os.Getenv(manifestEnv) == "" {
+		return pkgs, nil
+	}
+
+	seen := map[string]bool{}
+	importDirs := []string{}
+	for _, pkg := range pkgs {
+		if err := recordInputs(pkg.Filenames()...); err != nil {
+			return nil, err
+		}
+
+		stack := pkg.Types.Imports()
+		for len(stack) > 0 {
+			imported := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if seen[imported.Path()] {
+				continue
+			}
+			seen[imported.Path()] = true
+
+			buildPkg, err := build.Import(imported.Path(), pkg.Dir, build.FindOnly)
+			if err != nil || buildPkg.Goroot {
+				continue
+			}
+			importDirs = append(importDirs, buildPkg.Dir)
+			stack = append(stack, imported.Imports()...)
+		}
+	}
+	return pkgs, recordDirs(importDirs...)
+}
+
+// readManifest reads paths of files in the manifest.
+func readManifest(manifest string) ([]string, error) {
+	f, err := os.Open(manifest)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	seen := map[string]bool{}
+	res := []string{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line != "" && !seen[line] {
+			seen[line] = true
+			res = append(res, line)
+		}
+	}
+	return res, scanner.Err()
+}
+
+// hashFile returns the SHA-256 hash of the file or an empty string if it does not exist.
+func hashFile(filename string) (string, error) {
+	b, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// hashFiles returns hashes of the files.
+func hashFiles(filenames []string) (map[string]string, error) {
+	res := map[string]string{}
+	for _, filename := range filenames {
+		h, err := hashFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		res[filename] = h
+	}
+	return res, nil
+}
+
+// runCache is hashes of a script and files at the last successful run.
+type runCache struct {
+	Script  string            `json:"script"`
+	Inputs  map[string]string `json:"inputs"`
+	Outputs map[string]string `json:"outputs"`
+}
+
+// runCachePath returns the path of the cache for the directive.
+func runCachePath(cacheDir string, gd *query.GenerateDirective) (string, error) {
+	dir, err := filepath.Abs(gd.Dir)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(dir + "\x00" + gd.Command))
+	return filepath.Join(cacheDir, hex.EncodeToString(sum[:])+".json"), nil
+}
+
+func loadRunCache(filename string) (*runCache, error) {
+	b, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	c := &runCache{}
+	if err := json.Unmarshal(b, c); err != nil {
+		// Broken caches are ignored
+		return nil, nil
+	}
+	return c, nil
+}
+
+func saveRunCache(filename string, c *runCache) error {
+	b, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return err
+	}
+	_, err = writeFileIfChanged(filename, b)
+	return err
+}
+
+// matchHashes reports whether the files have the hashes.
+func matchHashes(hashes map[string]string) (bool, error) {
+	for filename, h := range hashes {
+		current, err := hashFile(filename)
+		if err != nil {
+			return false, err
+		}
+		if current != h {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// isUpToDate reports whether the script and files are not changed since the cache is saved.
+func (c *runCache) isUpToDate(script string) (bool, error) {
+	if c == nil {
+		return false, nil
+	}
+
+	h, err := hashFile(script)
+	if err != nil || h != c.Script {
+		return false, err
+	}
+	if ok, err := matchHashes(c.Inputs); !ok || err != nil {
+		return false, err
+	}
+	return matchHashes(c.Outputs)
+}
@@ -0,0 +1,102 @@
+package command
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestManifest(t *testing.T) {
+	dir := t.TempDir()
+	manifest := filepath.Join(dir, "manifest")
+
+	// Nothing is recorded without the manifest
+	t.Setenv(manifestEnv, "")
+	if err := recordInputs(filepath.Join(dir, "a.go")); err != nil {
+		t.Fatal(err)
+	}
+	if files, err := readManifest(manifest); err != nil || files != nil {
+		t.Errorf("readManifest() = %v, %v", files, err)
+	}
+
+	t.Setenv(manifestEnv, manifest)
+	for _, filenames := range [][]string{{"a.go", "b.go"}, {"a.go"}, {"c.go"}} {
+		for i, filename := range filenames {
+			filenames[i] = filepath.Join(dir, filename)
+		}
+		if err := recordInputs(filenames...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files, err := readManifest(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{filepath.Join(dir, "a.go"), filepath.Join(dir, "b.go"), filepath.Join(dir, "c.go")}
+	if !reflect.DeepEqual(files, want) {
+		t.Errorf("readManifest() = %v, want %v", files, want)
+	}
+}
+
+func TestRunCache(t *testing.T) {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "gen.sh")
+	input := filepath.Join(dir, "input.go")
+	missing := filepath.Join(dir, "missing.go")
+	for _, filename := range []string{script, input} {
+		if err := ioutil.WriteFile(filename, []byte(filename), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cachePath := filepath.Join(dir, "cache", "run.json")
+	if c, err := loadRunCache(cachePath); err != nil || c != nil {
+		t.Fatalf("loadRunCache() = %v, %v", c, err)
+	}
+
+	c := &runCache{}
+	var err error
+	if c.Script, err = hashFile(script); err != nil {
+		t.Fatal(err)
+	}
+	if c.Inputs, err = hashFiles([]string{input, missing}); err != nil {
+		t.Fatal(err)
+	}
+	if err := saveRunCache(cachePath, c); err != nil {
+		t.Fatal(err)
+	}
+	if c, err = loadRunCache(cachePath); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := c.isUpToDate(script); !ok || err != nil {
+		t.Errorf("isUpToDate() = %v, %v", ok, err)
+	}
+
+	// Files which did not exist are also checked
+	if err := ioutil.WriteFile(missing, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := c.isUpToDate(script); ok || err != nil {
+		t.Errorf("isUpToDate() after creating a file = %v, %v", ok, err)
+	}
+	if err := os.Remove(missing); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(script, []byte("changed"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := c.isUpToDate(script); ok || err != nil {
+		t.Errorf("isUpToDate() after changing the script = %v, %v", ok, err)
+	}
+
+	// Broken caches are ignored
+	if err := ioutil.WriteFile(cachePath, []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if c, err := loadRunCache(cachePath); err != nil || c != nil {
+		t.Errorf("loadRunCache() of a broken cache = %v, %v", c, err)
+	}
+}
@@ -0,0 +1,253 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"go/types"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+type mockParam struct {
+	Name string
+	// Field is the field name in the struct of the call.
+	Field string
+	// Type is the type in the struct of the call. Variadic parameters are slices.
+	Type     string
+	Variadic bool
+}
+
+type mockMethod struct {
+	Name    string
+	Params  []*mockParam
+	Results []string
+}
+
+// Signature renders parameters and results.
+func (m *mockMethod) Signature() string {
+	b := &strings.Builder{}
+	b.WriteString("(")
+	for i, p := range m.Params {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(p.Name)
+		b.WriteString(" ")
+		if p.Variadic {
+			b.WriteString("..." + strings.TrimPrefix(p.Type, "[]"))
+		} else {
+			b.WriteString(p.Type)
+		}
+	}
+	b.WriteString(")")
+
+	switch len(m.Results) {
+	case 0:
+	case 1:
+		b.WriteString(" " + m.Results[0])
+	default:
+		b.WriteString(" (" + strings.Join(m.Results, ", ") + ")")
+	}
+	return b.String()
+}
+
+// Args renders arguments to pass the parameters to the function.
+func (m *mockMethod) Args() string {
+	args := make([]string, len(m.Params))
+	for i, p := range m.Params {
+		args[i] = p.Name
+		if p.Variadic {
+			args[i] += "..."
+		}
+	}
+	return strings.Join(args, ", ")
+}
+
+func newMockMethod(fn *types.Func, qualifier types.Qualifier) *mockMethod {
+	sig := fn.Type().(*types.Signature)
+	method := &mockMethod{Name: fn.Name()}
+
+	params := sig.Params()
+	for i := 0; i < params.Len(); i++ {
+		v := params.At(i)
+		name := v.Name()
+		// "m" is the receiver
+		if name == "" || name == "_" || name == "m" {
+			name = fmt.Sprint("arg", i)
+		}
+		method.Params = append(method.Params, &mockParam{
+			Name:     name,
+			Field:    upperFirst(name),
+			Type:     types.TypeString(v.Type(), qualifier),
+			Variadic: sig.Variadic() && i == params.Len()-1,
+		})
+	}
+
+	results := sig.Results()
+	for i := 0; i < results.Len(); i++ {
+		method.Results = append(method.Results, types.TypeString(results.At(i).Type(), qualifier))
+	}
+	return method
+}
+
+type mockTemplateData struct {
+	Name      string
+	Interface string
+	Methods   []*mockMethod
+}
+
+const mockTemplate = `{{$m := .Name -}}
+var _ {{.Interface}} = (*{{$m}})(nil)
+
+// {{$m}} is a mock of {{.Interface}}.
+type {{$m}} struct {
+{{- range .Methods}}
+	// {{.Name}}Func is called by {{.Name}}.
+	{{.Name}}Func func{{.Signature}}
+{{- end}}
+
+	mu    sync.Mutex
+	calls struct {
+{{- range .Methods}}
+		{{.Name}} []{{$m}}{{.Name}}Call
+{{- end}}
+	}
+}
+{{- range .Methods}}
+{{$call := print $m .Name "Call"}}
+// {{$call}} is arguments of a call of {{.Name}}.
+type {{$call}} struct {
+{{- range .Params}}
+	{{.Field}} {{.Type}}
+{{- end}}
+{{- if .Params}}
+{{end}}}
+
+// {{.Name}} records the call and calls {{.Name}}Func.
+func (m *{{$m}}) {{.Name}}{{.Signature}} {
+	if m.{{.Name}}Func == nil {
+		panic("{{$m}}.{{.Name}}Func is not set")
+	}
+
+	m.mu.Lock()
+	m.calls.{{.Name}} = append(m.calls.{{.Name}}, {{$call}}{
+{{- range .Params}}
+		{{.Field}}: {{.Name}},
+{{- end}}
+	})
+	m.mu.Unlock()
+
+	{{if .Results}}return {{end}}m.{{.Name}}Func({{.Args}})
+}
+
+// {{.Name}}Calls returns arguments of calls of {{.Name}}.
+func (m *{{$m}}) {{.Name}}Calls() []{{$call}} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]{{$call}}(nil), m.calls.{{.Name}}...)
+}
+
+// {{.Name}}CallCount returns the number of calls of {{.Name}}.
+func (m *{{$m}}) {{.Name}}CallCount() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.calls.{{.Name}})
+}
+{{- end}}
+`
+
+func newMock(c *config) *cobra.Command {
+	opts := &generatorOptions{config: c}
+	name := ""
+	pkgName := ""
+
+	cmd := &cobra.Command{
+		Use:   "mock INTERFACE [package]",
+		Short: "Generate a mock implementation of the interface",
+		Long: `Generate a mock implementation of the interface.
+
+The mock has a function field <METHOD>Func for each method including methods of embedded interfaces.
+Calls are recorded with arguments, and <METHOD>Calls and <METHOD>CallCount return them safely from multiple goroutines.
+Packages of parameters and results are imported.
+With --package different from the package of the interface, the interface is referred to through its import path.`,
+		Example: `  gogtok mock Store --package storetest -o storetest/store_mock.go`,
+		Args:    cobra.RangeArgs(1, 2),
+		RunE: func(_ *cobra.Command, args []string) error {
+			ifaceName := args[0]
+			dir := "."
+			if len(args) > 1 {
+				dir = args[1]
+			}
+
+			pkg, obj, err := opts.lookupType(dir, ifaceName)
+			if err != nil {
+				return err
+			}
+			iface, ok := obj.Type().Underlying().(*types.Interface)
+			if !ok {
+				return fmt.Errorf("not an interface: %s", ifaceName)
+			}
+			if iface.NumMethods() == 0 {
+				return fmt.Errorf("no methods: %s", ifaceName)
+			}
+
+			if pkgName == "" {
+				pkgName = pkg.Types.Name()
+			}
+			imports := newGeneratorImports("")
+			if pkgName == pkg.Types.Name() {
+				imports.pkgPath = pkg.Types.Path()
+			} else {
+				if !obj.Exported() {
+					return fmt.Errorf("unexported interface cannot be mocked in another package: %s", ifaceName)
+				}
+				for i := 0; i < iface.NumMethods(); i++ {
+					if !iface.Method(i).Exported() {
+						return fmt.Errorf("interface with unexported methods cannot be mocked in another package: %s", ifaceName)
+					}
+				}
+			}
+			// Reserve the name before packages of parameters
+			imports.qualifier(types.NewPackage("sync", "sync"))
+
+			if name == "" {
+				name = "Mock" + ifaceName
+				if !obj.Exported() {
+					name = "mock" + upperFirst(ifaceName)
+				}
+			}
+			if name == ifaceName && pkgName == pkg.Types.Name() {
+				return errors.New("name of the mock conflicts with the interface")
+			}
+
+			data := &mockTemplateData{
+				Name:      name,
+				Interface: types.TypeString(obj.Type(), imports.qualifier),
+			}
+			// Methods are sorted by names including methods of embedded interfaces
+			for i := 0; i < iface.NumMethods(); i++ {
+				data.Methods = append(data.Methods, newMockMethod(iface.Method(i), imports.qualifier))
+			}
+
+			body, err := executeTemplate(mockTemplate, data)
+			if err != nil {
+				return err
+			}
+
+			outDir := dir
+			if opts.output != "" {
+				outDir = filepath.Dir(opts.output)
+			}
+			return opts.write(outDir, pkgName, body, imports.imports...)
+		},
+	}
+
+	opts.addFlags(cmd)
+	flags := cmd.Flags()
+	flags.StringVar(&name, "name", name, "Name of the mock (default Mock<INTERFACE>)")
+	flags.StringVar(&pkgName, "package", pkgName, "Package name of the generated file (default the package of the interface)")
+
+	return cmd
+}
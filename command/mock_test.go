@@ -0,0 +1,119 @@
+package command
+
+import "testing"
+
+func TestMock(t *testing.T) {
+	dir := newTestModule(t, map[string]string{
+		"store.go": `package test
+
+import (
+	"context"
+	"io"
+)
+
+type cache interface {
+	lookup(m map[string]int, _ string) int
+}
+
+type Store interface {
+	io.Closer
+	Get(ctx context.Context, key string) ([]byte, error)
+	Put(context.Context, string, ...[]byte)
+}
+`,
+		"storetest/store_test.go": `package storetest
+
+import (
+	"context"
+	"testing"
+
+	"example.com/test"
+)
+
+func TestMockStore(t *testing.T) {
+	m := &MockStore{
+		GetFunc: func(ctx context.Context, key string) ([]byte, error) {
+			return []byte(key), nil
+		},
+		PutFunc: func(context.Context, string, ...[]byte) {},
+	}
+	var s test.Store = m
+
+	if b, err := s.Get(context.Background(), "k"); err != nil || string(b) != "k" {
+		t.Errorf("Get() = %s, %v", b, err)
+	}
+	s.Put(context.Background(), "k", []byte("a"), []byte("b"))
+	s.Put(context.Background(), "l")
+
+	if n := m.PutCallCount(); n != 2 {
+		t.Errorf("PutCallCount() = %d", n)
+	}
+	if calls := m.PutCalls(); calls[0].Arg1 != "k" || len(calls[0].Arg2) != 2 || calls[1].Arg1 != "l" {
+		t.Errorf("PutCalls() = %v", calls)
+	}
+	if calls := m.GetCalls(); len(calls) != 1 || calls[0].Key != "k" {
+		t.Errorf("GetCalls() = %v", calls)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Close must panic without CloseFunc")
+		}
+	}()
+	s.Close()
+}
+`,
+		"cache_test.go": `package test
+
+import "testing"
+
+func TestMockCache(t *testing.T) {
+	m := &mockCache{
+		lookupFunc: func(m map[string]int, key string) int {
+			return m[key]
+		},
+	}
+	var c cache = m
+	if v := c.lookup(map[string]int{"k": 1}, "k"); v != 1 {
+		t.Errorf("lookup() = %d", v)
+	}
+	if calls := m.lookupCalls(); len(calls) != 1 || calls[0].Arg0["k"] != 1 || calls[0].Arg1 != "k" {
+		t.Errorf("lookupCalls() = %v", calls)
+	}
+}
+`,
+	})
+
+	generate(t, dir, "storetest/store_gen.go", "mock", "Store", "--package", "storetest")
+	// Generating over the previous output replaces it
+	for i := 0; i < 2; i++ {
+		generate(t, dir, "cache_gen.go", "mock", "cache")
+	}
+	goTest(t, dir)
+}
+
+func TestMockErrors(t *testing.T) {
+	dir := newTestModule(t, map[string]string{
+		"store.go": `package test
+
+type Store interface {
+	Get(key string) string
+	close()
+}
+
+type reader interface {
+	Read() string
+}
+
+type Empty interface{}
+
+type Value struct{}
+`,
+	})
+
+	generateError(t, dir, "name of the mock conflicts with the interface", "mock", "Store", "--name", "Store")
+	generateError(t, dir, "interface with unexported methods cannot be mocked in another package: Store", "mock", "Store", "--package", "storetest")
+	generateError(t, dir, "unexported interface cannot be mocked in another package: reader", "mock", "reader", "--package", "storetest")
+	generateError(t, dir, "no methods: Empty", "mock", "Empty")
+	generateError(t, dir, "not an interface: Value", "mock", "Value")
+}
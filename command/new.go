@@ -9,6 +9,7 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"github.com/spf13/cobra"
+	"github.com/utisam/gogtok/query"
 )
 
 const newTemplate = `#!{{.Shebang}}
@@ -22,7 +23,8 @@ render_file() {
 	# TODO: generate code
 }
 
-render_file | goimports > "{{.FileName}}.go"
+rendered="$({{if .Set}}set {{.Set}}; {{end}}render_file)"
+echo "$rendered" | {{.Formatter}} | gogtok write "{{.FileName}}.go"
 `
 
 type newTemplateData struct {
@@ -32,6 +34,7 @@ type newTemplateData struct {
 	CDFileDir   bool
 	FileName    string
 	Package     string
+	Formatter   string
 }
 
 func newNew() *cobra.Command {
@@ -40,6 +43,7 @@ func newNew() *cobra.Command {
 	set := "-eu"
 	sourceGoEnv := false
 	cdFileDir := false
+	formatter := "gogtok fmt"
 
 	cmd := &cobra.Command{
 		Use:  "new",
@@ -53,7 +57,7 @@ func newNew() *cobra.Command {
 			}
 
 			if packageName == "" {
-				pn, err := getPackageName(path.Dir(filePath))
+				pn, err := query.PackageName(path.Dir(filePath))
 				if err != nil {
 					logrus.WithError(err).Warn("Failed to get package name from other .go files. Directory name will be used.")
 
@@ -87,6 +91,7 @@ func newNew() *cobra.Command {
 				CDFileDir:   cdFileDir,
 				FileName:    fileName,
 				Package:     packageName,
+				Formatter:   formatter,
 			}); err != nil {
 				return err
 			}
@@ -103,6 +108,7 @@ func newNew() *cobra.Command {
 	flags.BoolVarP(&sourceGoEnv, "source-go-env", "s", sourceGoEnv, "Source 'go env'")
 	flags.BoolVarP(&cdFileDir, "cd-file-dir", "c", cdFileDir, "'cd FILE_DIR'")
 	flags.StringVar(&set, "set", set, "set")
+	flags.StringVar(&formatter, "formatter", formatter, "Command to format the rendered source (e.g. goimports)")
 
 	return cmd
 }
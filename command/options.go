@@ -0,0 +1,281 @@
+package command
+
+import (
+	"fmt"
+	"go/types"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+type optionsField struct {
+	Name string
+	Type string
+	// Func is the name of the option or the method of the builder.
+	Func    string
+	Default string
+	// IsZero is the condition that the required field is not set.
+	IsZero string
+}
+
+type optionsTemplateData struct {
+	Type       string
+	Option     string
+	New        string
+	Builder    string
+	NewBuilder string
+	Validate   string
+	Fields     []*optionsField
+	Defaults   []*optionsField
+	Required   []*optionsField
+}
+
+const optionsTemplate = `{{$t := .Type -}}
+// {{.Option}} configures {{$t}}.
+type {{.Option}} func(*{{$t}})
+{{- range .Fields}}
+
+// {{.Func}} sets {{.Name}} of {{$t}}.
+func {{.Func}}(v {{.Type}}) {{$.Option}} {
+	return func(x *{{$t}}) {
+		x.{{.Name}} = v
+	}
+}
+{{- end}}
+
+// {{.New}} creates {{$t}} with the options.
+func {{.New}}(opts ...{{.Option}}) (*{{$t}}, error) {
+	x := &{{$t}}{
+{{- range .Defaults}}
+		{{.Name}}: {{.Default}},
+{{- end}}
+	}
+	for _, opt := range opts {
+		opt(x)
+	}
+{{- if .Required}}
+	if err := {{.Validate}}(x); err != nil {
+		return nil, err
+	}
+{{- end}}
+	return x, nil
+}
+`
+
+const builderTemplate = `{{$t := .Type -}}
+{{$b := .Builder -}}
+// {{$b}} builds {{$t}}.
+type {{$b}} struct {
+	x {{$t}}
+}
+
+// {{.NewBuilder}} creates {{$b}} with default values.
+func {{.NewBuilder}}() *{{$b}} {
+	return &{{$b}}{
+		x: {{$t}}{
+{{- range .Defaults}}
+			{{.Name}}: {{.Default}},
+{{- end}}
+		},
+	}
+}
+{{- range .Fields}}
+
+// {{.Func}} sets {{.Name}} of {{$t}}.
+func (b *{{$b}}) {{.Func}}(v {{.Type}}) *{{$b}} {
+	b.x.{{.Name}} = v
+	return b
+}
+{{- end}}
+
+// Build returns a copy of {{$t}} built so far.
+func (b *{{$b}}) Build() (*{{$t}}, error) {
+	x := b.x
+{{- if .Required}}
+	if err := {{.Validate}}(&x); err != nil {
+		return nil, err
+	}
+{{- end}}
+	return &x, nil
+}
+`
+
+const optionsValidateTemplate = `{{$t := .Type -}}
+
+// {{.Validate}} returns an error if required fields of {{$t}} are not set.
+func {{.Validate}}(x *{{$t}}) error {
+{{- range .Required}}
+	if {{.IsZero}} {
+		return errors.New("{{$t}}.{{.Name}} is required")
+	}
+{{- end}}
+	return nil
+}
+`
+
+// parseOptionTag parses the tag such as "required" or "default=time.Second".
+// The default value must be the last because it may contain commas.
+func parseOptionTag(tag string) (skip, required bool, def string, err error) {
+	for tag != "" {
+		if strings.HasPrefix(tag, "default=") {
+			return false, required, strings.TrimPrefix(tag, "default="), nil
+		}
+
+		item := tag
+		tag = ""
+		if i := strings.IndexByte(item, ','); i >= 0 {
+			item, tag = item[:i], item[i+1:]
+		}
+		switch item {
+		case "-":
+			skip = true
+		case "required":
+			required = true
+		default:
+			return false, false, "", fmt.Errorf("unknown option: %s", item)
+		}
+	}
+	return skip, required, "", nil
+}
+
+func newOptions(c *config) *cobra.Command {
+	opts := &generatorOptions{config: c}
+	builder := false
+	prefix := false
+	tagKey := "option"
+
+	cmd := &cobra.Command{
+		Use:   "options TYPE [package]",
+		Short: "Generate functional options or a builder of the struct",
+		Long: `Generate functional options or a builder of the struct.
+
+An option With<FIELD> is generated for each field, and New<TYPE> creates the struct with options.
+With --prefix, options are named With<TYPE><FIELD> to avoid conflicts with options of other structs in the package.
+With --builder, New<TYPE>Builder creates a builder having With<FIELD> methods and Build.
+Fields are controlled by the tag:
+  option:"-"                  Skip the field
+  option:"required"           Return an error if the field is the zero value
+  option:"default=EXPR"       Initialize the field with the expression
+Names are unexported if the struct is unexported.
+Generation fails if the names conflict with existing declarations in the package.`,
+		Example: `  gogtok options Server -o server_options.go`,
+		Args:    cobra.RangeArgs(1, 2),
+		RunE: func(_ *cobra.Command, args []string) error {
+			typeName := args[0]
+			dir := "."
+			if len(args) > 1 {
+				dir = args[1]
+			}
+
+			pkg, obj, err := opts.lookupType(dir, typeName)
+			if err != nil {
+				return err
+			}
+			st, ok := obj.Type().Underlying().(*types.Struct)
+			if !ok {
+				return fmt.Errorf("not a struct: %s", typeName)
+			}
+
+			exported := func(s string) string {
+				if obj.Exported() {
+					return upperFirst(s)
+				}
+				return s
+			}
+			data := &optionsTemplateData{
+				Type:       typeName,
+				Option:     typeName + "Option",
+				New:        exported("new") + upperFirst(typeName),
+				Builder:    typeName + "Builder",
+				NewBuilder: exported("new") + upperFirst(typeName) + "Builder",
+				Validate:   "validate" + upperFirst(typeName),
+			}
+			// Options and a builder of the type may be generated in the same package
+			if builder {
+				data.Validate += "Builder"
+			}
+
+			funcPrefix := exported("with")
+			if prefix && !builder {
+				funcPrefix += upperFirst(typeName)
+			}
+
+			imports := newGeneratorImports(pkg.Types.Path())
+			for i := 0; i < st.NumFields(); i++ {
+				v := st.Field(i)
+				skip, required, def, err := parseOptionTag(reflect.StructTag(st.Tag(i)).Get(tagKey))
+				if err != nil {
+					return fmt.Errorf("%s.%s: %v", typeName, v.Name(), err)
+				}
+				if skip || v.Name() == "_" {
+					continue
+				}
+
+				field := &optionsField{
+					Name:    v.Name(),
+					Type:    types.TypeString(v.Type(), imports.qualifier),
+					Func:    funcPrefix + upperFirst(v.Name()),
+					Default: def,
+				}
+				data.Fields = append(data.Fields, field)
+				if def != "" {
+					data.Defaults = append(data.Defaults, field)
+				}
+				if required {
+					field.IsZero, err = zeroCondition("x."+v.Name(), v.Type(), imports.qualifier)
+					if err != nil {
+						return err
+					}
+					data.Required = append(data.Required, field)
+				}
+			}
+
+			// Declarations in previous outputs for the type are replaced
+			conflicts := func(name string) bool {
+				obj := pkg.Types.Scope().Lookup(name)
+				return obj != nil && !opts.replaces(pkg, obj, typeName)
+			}
+			names := []string{data.Option, data.New}
+			if builder {
+				names = []string{data.Builder, data.NewBuilder}
+			}
+			if len(data.Required) > 0 {
+				names = append(names, data.Validate)
+			}
+			for _, name := range names {
+				if conflicts(name) {
+					return fmt.Errorf("%s conflicts with the existing declaration", name)
+				}
+			}
+			if !builder {
+				for _, field := range data.Fields {
+					if conflicts(field.Func) {
+						return fmt.Errorf("%s conflicts with the existing declaration; --prefix names options With<TYPE><FIELD>", field.Func)
+					}
+				}
+			}
+
+			text := optionsTemplate
+			if builder {
+				text = builderTemplate
+			}
+			if len(data.Required) > 0 {
+				text += optionsValidateTemplate
+			}
+			body, err := executeTemplate(text, data)
+			if err != nil {
+				return err
+			}
+			return opts.write(dir, pkg.Types.Name(), body, imports.imports...)
+		},
+	}
+
+	opts.addFlags(cmd)
+	flags := cmd.Flags()
+	flags.BoolVar(&builder, "builder", builder, "Generate a builder instead of functional options")
+	flags.BoolVar(&prefix, "prefix", prefix, "Prefix options with the type name such as With<TYPE><FIELD>")
+	flags.StringVar(&tagKey, "tag", tagKey, "Key of the tag to control fields")
+
+	return cmd
+}
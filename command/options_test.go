@@ -0,0 +1,83 @@
+package command
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestOptions(t *testing.T) {
+	dir := newTestModule(t, map[string]string{
+		"server.go": `package test
+
+import "time"
+
+type Server struct {
+	Name    string        ` + "`option:\"required\"`" + `
+	Timeout time.Duration ` + "`option:\"default=time.Second\"`" + `
+	secret  string        ` + "`option:\"-\"`" + `
+}
+
+type Client struct {
+	Name string
+}
+
+type Request struct {
+	Name string ` + "`option:\"required\"`" + `
+}
+`,
+		"server_test.go": `package test
+
+import (
+	"testing"
+	"time"
+)
+
+func TestServer(t *testing.T) {
+	s, err := NewServer(WithName("s"))
+	if err != nil || s.Name != "s" || s.Timeout != time.Second {
+		t.Errorf("NewServer() = %+v, %v", s, err)
+	}
+	if _, err := NewServer(); err == nil {
+		t.Error("required fields must be set")
+	}
+}
+
+func TestClient(t *testing.T) {
+	c, err := NewClient(WithClientName("c"))
+	if err != nil || c.Name != "c" {
+		t.Errorf("NewClient() = %+v, %v", c, err)
+	}
+}
+
+func TestRequest(t *testing.T) {
+	r, err := NewRequestBuilder().WithName("r").Build()
+	if err != nil || r.Name != "r" {
+		t.Errorf("Build() = %+v, %v", r, err)
+	}
+	if _, err := NewRequestBuilder().Build(); err == nil {
+		t.Error("required fields must be set")
+	}
+	if _, err := NewRequest(); err == nil {
+		t.Error("required fields must be set")
+	}
+}
+`,
+	})
+
+	// Previous outputs written through stdout must be replaced
+	for i := 0; i < 2; i++ {
+		generateStdout(t, dir, "server_gen.go", "options", "Server")
+	}
+
+	err := runGogtok("options", "Client", dir)
+	if err == nil || !strings.Contains(err.Error(), "WithName conflicts") {
+		t.Errorf("options of the same names must conflict: %v", err)
+	}
+	generate(t, dir, "client_gen.go", "options", "Client", "--prefix")
+	// Options and a builder of the same type can be generated into the package
+	generate(t, dir, "request_options_gen.go", "options", "Request", "--prefix")
+	for i := 0; i < 2; i++ {
+		generate(t, dir, "request_builder_gen.go", "options", "Request", "--builder")
+	}
+	goTest(t, dir)
+}
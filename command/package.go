@@ -2,17 +2,9 @@ package command
 
 import (
 	"fmt"
-	"go/build"
-	"go/parser"
-	"go/token"
-	"io/ioutil"
-	"os"
-	"path"
-	"path/filepath"
-	"strings"
 
-	"github.com/sirkon/goproxy/gomod"
 	"github.com/spf13/cobra"
+	"github.com/utisam/gogtok/query"
 )
 
 func newPackage() *cobra.Command {
@@ -38,7 +30,11 @@ func newPackageName() *cobra.Command {
 			}
 
 			for _, dir := range dirs {
-				packageName, err := getPackageName(dir)
+				if err := recordDirs(dir); err != nil {
+					return err
+				}
+
+				packageName, err := query.PackageName(dir)
 				if err != nil {
 					return err
 				}
@@ -51,40 +47,6 @@ func newPackageName() *cobra.Command {
 	return cmd
 }
 
-func getPackageName(dir string) (string, error) {
-	fileInfos, err := ioutil.ReadDir(dir)
-	if err != nil {
-		return "", err
-	}
-
-	for _, fileInfo := range fileInfos {
-		fileName := path.Join(dir, fileInfo.Name())
-		if fileInfo.IsDir() || !strings.HasSuffix(fileName, ".go") {
-			continue
-		}
-
-		reader, err := os.Open(fileName)
-		if err != nil {
-			return "", err
-		}
-
-		fset := token.NewFileSet()
-		f, err := parser.ParseFile(fset, fileName, reader, parser.PackageClauseOnly)
-		if err != nil {
-			return "", err
-		}
-
-		packageName := f.Name.Name
-		if strings.HasSuffix(packageName, "_test") {
-			continue
-		}
-
-		return packageName, nil
-	}
-
-	return "", fmt.Errorf("no .go files: %s", dir)
-}
-
 func newPackagePath() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "path",
@@ -96,11 +58,15 @@ func newPackagePath() *cobra.Command {
 			}
 
 			for _, dir := range dirs {
-				packageName, err := getPackagePath(dir)
+				packageName, err := query.PackagePath(dir)
 				if err != nil {
 					return err
 				}
 
+				if err := recordGoMod(dir); err != nil {
+					return err
+				}
+
 				fmt.Println(packageName)
 			}
 			return nil
@@ -108,73 +74,3 @@ func newPackagePath() *cobra.Command {
 	}
 	return cmd
 }
-
-func getPackagePath(dir string) (string, error) {
-	absPath, err := filepath.Abs(dir)
-	if err != nil {
-		return "", err
-	}
-
-	gopath := os.Getenv("GOPATH")
-	if gopath == "" {
-		gopath = build.Default.GOPATH
-	}
-	goSRCPath := path.Join(gopath, "src")
-
-	go111Module := os.Getenv("GO111MODULE")
-	if go111Module != "on" && go111Module != "off" {
-		if strings.HasPrefix(absPath, goSRCPath) {
-			go111Module = "off"
-		} else {
-			go111Module = "on"
-		}
-	}
-
-	if go111Module == "off" {
-		if !strings.HasPrefix(absPath, goSRCPath) {
-			return "", fmt.Errorf("%s is not in GOPATH", absPath)
-		}
-
-		return absPath[len(goSRCPath)+1:], nil
-	}
-
-	filePath, err := findGoModFile(absPath)
-	if err != nil {
-		return "", err
-	}
-
-	f, err := os.Open(filePath)
-	if err != nil {
-		return "", err
-	}
-
-	b, err := ioutil.ReadAll(f)
-	if err != nil {
-		return "", err
-	}
-
-	mod, err := gomod.Parse(filePath, b)
-	if err != nil {
-		return "", err
-	}
-
-	return path.Join(mod.Name, dir), nil
-}
-
-func findGoModFile(absDir string) (string, error) {
-	for {
-		filePath := path.Join(absDir, "go.mod")
-
-		info, err := os.Stat(filePath)
-		if err == nil && !info.IsDir() {
-			return filePath, nil
-		} else if !os.IsNotExist(err) {
-			return "", err
-		}
-
-		absDir = path.Dir(absDir)
-		if absDir == "/" {
-			return "", os.ErrNotExist
-		}
-	}
-}
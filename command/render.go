@@ -0,0 +1,300 @@
+package command
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"text/template"
+
+	"github.com/spf13/cobra"
+	"github.com/utisam/gogtok/query"
+)
+
+// templateMethod is a method returned by "methods" in templates.
+type templateMethod struct {
+	Name    string
+	Params  []*query.Param
+	Results []*query.Param
+}
+
+// templateData is the data passed to templates.
+type templateData struct {
+	// Args are arguments following the template.
+	Args []string
+}
+
+// templateRenderer provides functions querying Go files to templates.
+type templateRenderer struct {
+	files   map[string]*ast.File
+	imports []*query.Import
+}
+
+func newTemplateRenderer() *templateRenderer {
+	return &templateRenderer{
+		files: map[string]*ast.File{},
+	}
+}
+
+func (r *templateRenderer) parseFile(filename string) (*ast.File, error) {
+	if f, ok := r.files[filename]; ok {
+		return f, nil
+	}
+
+	if err := recordInputs(filename); err != nil {
+		return nil, err
+	}
+	f, err := query.ParseFile(token.NewFileSet(), filename)
+	if err != nil {
+		return nil, err
+	}
+	r.files[filename] = f
+	return f, nil
+}
+
+// lookupType returns the type spec declared in the file.
+func (r *templateRenderer) lookupType(filename, typeName string) (*ast.File, *ast.TypeSpec, error) {
+	f, err := r.parseFile(filename)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var found *ast.TypeSpec
+	ast.Inspect(f, func(n ast.Node) bool {
+		if typeSpec, ok := n.(*ast.TypeSpec); ok && typeSpec.Name.Name == typeName {
+			found = typeSpec
+		}
+		return found == nil
+	})
+	if found == nil {
+		return nil, nil, fmt.Errorf("type not found in %s: %s", filename, typeName)
+	}
+	return f, found, nil
+}
+
+func (r *templateRenderer) fields(filename, typeName string) ([]*query.Field, error) {
+	f, _, err := r.lookupType(filename, typeName)
+	if err != nil {
+		return nil, err
+	}
+	return query.Fields(f, typeName), nil
+}
+
+func (r *templateRenderer) methods(filename, typeName string) ([]*templateMethod, error) {
+	f, typeSpec, err := r.lookupType(filename, typeName)
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	if iface, ok := typeSpec.Type.(*ast.InterfaceType); ok {
+		for _, method := range iface.Methods.List {
+			for _, name := range method.Names {
+				names = append(names, name.Name)
+			}
+		}
+	} else {
+		for _, fn := range query.Funcs(f) {
+			if fn.Recv == typeName {
+				names = append(names, fn.Name)
+			}
+		}
+	}
+
+	res := make([]*templateMethod, len(names))
+	for i, name := range names {
+		params, results, err := query.Signature(f, typeName+"."+name)
+		if err != nil {
+			return nil, err
+		}
+		res[i] = &templateMethod{
+			Name:    name,
+			Params:  params,
+			Results: results,
+		}
+	}
+	return res, nil
+}
+
+func (r *templateRenderer) params(filename, funcName string) ([]*query.Param, error) {
+	f, err := r.parseFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	params, _, err := query.Signature(f, funcName)
+	return params, err
+}
+
+func (r *templateRenderer) results(filename, funcName string) ([]*query.Param, error) {
+	f, err := r.parseFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	_, results, err := query.Signature(f, funcName)
+	return results, err
+}
+
+func (r *templateRenderer) enums(filename, typeName string) ([]*query.Value, error) {
+	f, err := r.parseFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []*query.Value{}
+	for _, value := range query.Values(f) {
+		if value.Const && value.Type == typeName && value.Name != "_" {
+			res = append(res, value)
+		}
+	}
+	return res, nil
+}
+
+func (r *templateRenderer) addImport(s string) (string, error) {
+	imp, err := query.ParseImport(s)
+	if err != nil {
+		return "", err
+	}
+	r.imports = append(r.imports, imp)
+	return "", nil
+}
+
+func templateDir(dirs []string) (string, error) {
+	switch len(dirs) {
+	case 0:
+		return ".", nil
+	case 1:
+		return dirs[0], nil
+	}
+	return "", fmt.Errorf("too many arguments: %v", dirs)
+}
+
+func templatePackageName(dirs ...string) (string, error) {
+	dir, err := templateDir(dirs)
+	if err != nil {
+		return "", err
+	}
+	if err := recordDirs(dir); err != nil {
+		return "", err
+	}
+	return query.PackageName(dir)
+}
+
+func templatePackagePath(dirs ...string) (string, error) {
+	dir, err := templateDir(dirs)
+	if err != nil {
+		return "", err
+	}
+	if err := recordGoMod(dir); err != nil {
+		return "", err
+	}
+	return query.PackagePath(dir)
+}
+
+func (r *templateRenderer) funcMap() template.FuncMap {
+	funcs := template.FuncMap{
+		"fields":      r.fields,
+		"methods":     r.methods,
+		"params":      r.params,
+		"results":     r.results,
+		"enums":       r.enums,
+		"import":      r.addImport,
+		"packageName": templatePackageName,
+		"packagePath": templatePackagePath,
+	}
+	for name, fn := range generatorFuncs {
+		funcs[name] = fn
+	}
+	return funcs
+}
+
+func newRender(c *config) *cobra.Command {
+	output := ""
+	imports := []string{}
+	std := true
+	format := true
+
+	cmd := &cobra.Command{
+		Use:   "render TEMPLATE [args...]",
+		Short: "Render Go source with the template querying Go files",
+		Long: `Render Go source with the template querying Go files.
+
+The template is executed by text/template with following functions. Arguments are available as .Args.
+  fields FILE TYPE         Fields of the struct or methods of the interface (.Name, .Type, .Tag, .TagValue KEY)
+  methods FILE TYPE        Methods of the type declared in the file (.Name, .Params, .Results)
+  params FILE FUNC         Parameters of the function (.Name, .Type, .Index, .Variadic, .Ident, .Arg)
+  results FILE FUNC        Results of the function
+  enums FILE TYPE          Constants of the type (.Name, .Type)
+  packageName [DIR]        Package name of the directory
+  packagePath [DIR]        Package path of the directory
+  import SPEC              Resolve the package such as 'yaml "gopkg.in/yaml.v2"' if it is used
+  lower, upper, camel, pascal, snake, kebab, upperSnake, join, quote
+
+The rendered source is formatted and imports are fixed like "gogtok fmt".`,
+		Example: `  gogtok render -o user_gen.go user.tmpl User`,
+		Args:    cobra.MinimumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			filename := args[0]
+
+			if err := recordInputs(filename); err != nil {
+				return err
+			}
+
+			r := newTemplateRenderer()
+			tmpl, err := template.New(filepath.Base(filename)).Funcs(r.funcMap()).ParseFiles(filename)
+			if err != nil {
+				return err
+			}
+
+			b := &bytes.Buffer{}
+			if err := tmpl.Execute(b, &templateData{Args: args[1:]}); err != nil {
+				return err
+			}
+			src := b.Bytes()
+
+			if format {
+				explicit := append(c.imports(), r.imports...)
+				for _, s := range imports {
+					imp, err := query.ParseImport(s)
+					if err != nil {
+						return err
+					}
+					explicit = append(explicit, imp)
+				}
+
+				resolver, err := newImportResolver(explicit, std)
+				if err != nil {
+					return err
+				}
+
+				srcName := output
+				if srcName == "" {
+					srcName = filename
+				}
+				src, err = formatSource(srcName, src, resolver)
+				if err, ok := err.(*syntaxError); ok {
+					io.WriteString(os.Stderr, err.context)
+				}
+				if err != nil {
+					return err
+				}
+			}
+
+			if output != "" {
+				return writeOutput(output, src)
+			}
+			_, err = os.Stdout.Write(src)
+			return err
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVarP(&output, "output", "o", output, "File to write if it is changed instead of stdout")
+	flags.StringArrayVarP(&imports, "import", "i", imports, `Package to resolve missing imports (e.g. 'text/template' or 'name "path"')`)
+	flags.BoolVar(&std, "std", std, "Resolve standard packages whose names are unique")
+	flags.BoolVar(&format, "format", format, "Format the rendered source and fix imports")
+
+	return cmd
+}
@@ -0,0 +1,115 @@
+package command
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	dir := newTestModule(t, map[string]string{
+		"types.go": `package test
+
+import "context"
+
+type Kind int
+
+const (
+	KindA Kind = iota
+	_
+	KindB
+)
+
+type User struct {
+	UserID   int    ` + "`json:\"user_id\"`" + `
+	FullName string
+}
+
+type Store interface {
+	Get(ctx context.Context, id int) (*User, error)
+	Close() error
+}
+
+func (u *User) Rename(string) {}
+`,
+		// Queries and converters are rendered in comments
+		"render.tmpl": `{{$file := index .Args 0}}{{$dir := index .Args 1 -}}
+package {{packageName $dir}}
+
+// path: {{packagePath $dir}}
+{{range fields $file "User" -}}
+// field: {{.Name}} {{.Type}} {{.TagValue "json"}} {{snake .Name}} {{kebab .Name}} {{upperSnake .Name}} {{camel .Name}}
+{{end -}}
+{{range methods $file "Store" -}}
+// method: {{.Name}}({{range .Params}}{{.Arg}} {{.Type}}, {{end}}) {{len .Results}}
+{{end -}}
+{{range methods $file "User" -}}
+// user method: {{.Name}} {{(index .Params 0).Arg}}
+{{end -}}
+// params: {{range params $file "Store.Get"}}{{.Name}} {{end}}
+// results: {{range results $file "Store.Get"}}{{.Type}} {{end}}
+{{range enums $file "Kind" -}}
+// enum: {{.Name}} {{pascal (lower .Name)}} {{upper .Name}} {{quote .Name}}
+{{end -}}
+// join: {{join .Args ","}}
+{{import "yaml \"gopkg.in/yaml.v2\""}}
+var _ = yaml.Marshal
+var _ = strings.ToUpper
+`,
+	})
+	typesFile := filepath.Join(dir, "types.go")
+
+	out, err := gogtokStdout(t, "render", filepath.Join(dir, "render.tmpl"), typesFile, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `package test
+
+import (
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// path: example.com/test
+// field: UserID int user_id user_id user-id USER_ID userId
+// field: FullName string  full_name full-name FULL_NAME fullName
+// method: Get(ctx context.Context, id int, ) 2
+// method: Close() 1
+// user method: Rename p0
+// params: ctx id
+// results: *User error
+// enum: KindA Kinda KINDA "KindA"
+// enum: KindB Kindb KINDB "KindB"
+// join: ` + typesFile + `,` + dir + `
+
+var _ = yaml.Marshal
+var _ = strings.ToUpper
+`
+	if out != want {
+		t.Errorf("render =\n%s\nwant\n%s", out, want)
+	}
+
+	// Queries of missing declarations fail
+	tmpl := filepath.Join(dir, "missing.tmpl")
+	if err := ioutil.WriteFile(tmpl, []byte(`{{fields (index .Args 0) "Missing"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err = runGogtok("render", tmpl, typesFile)
+	if err == nil || !strings.Contains(err.Error(), "type not found in "+typesFile+": Missing") {
+		t.Errorf("missing types must be reported: %v", err)
+	}
+
+	// Outputs are written without formatting
+	if err := ioutil.WriteFile(tmpl, []byte("package {{packageName (index .Args 0)}}\nvar x = strings.ToUpper\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	output := filepath.Join(dir, "out.go")
+	if err := runGogtok("render", "--format=false", "-o", output, tmpl, dir); err != nil {
+		t.Fatal(err)
+	}
+	if b, err := ioutil.ReadFile(output); err != nil || string(b) != "package test\nvar x = strings.ToUpper\n" {
+		t.Errorf("render -o = %q, %v", b, err)
+	}
+}
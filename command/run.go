@@ -0,0 +1,491 @@
+package command
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+	"github.com/utisam/gogtok/query"
+)
+
+var (
+	// scriptOutputPattern matches "gogtok write FILE" and "gogtok COMMAND... -o FILE".
+	scriptOutputPattern = regexp.MustCompile(`gogtok\s+(?:write\s+|[^;&|\n]*?\s(?:-o|--output)(?:\s+|=))("[^"]*"|'[^']*'|[^\s;&|<>()]+)`)
+	scriptInputPattern  = regexp.MustCompile(`"[^"]*\.go"|'[^']*\.go'|[^\s"';&|<>()=]+\.go\b`)
+)
+
+// runJob is a script to be run by "gogtok run".
+type runJob struct {
+	gd   *query.GenerateDirective
+	name string
+	// inputs and outputs are absolute paths of Go files which the script reads and writes.
+	inputs  []string
+	outputs []string
+
+	dependents []*runJob
+	numDeps    int
+
+	err      error
+	skipped  bool
+	upToDate bool
+}
+
+// scriptWord unquotes the word in the script and expands variables defined by "go generate".
+func scriptWord(gd *query.GenerateDirective, word string) string {
+	if len(word) >= 2 && (word[0] == '"' || word[0] == '\'') {
+		word = word[1 : len(word)-1]
+	}
+	return os.Expand(word, gd.Getenv)
+}
+
+// newRunJob reads the script and finds Go files in it.
+func newRunJob(gd *query.GenerateDirective) (*runJob, error) {
+	b, err := ioutil.ReadFile(gd.Script)
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := filepath.Abs(gd.Dir)
+	if err != nil {
+		return nil, err
+	}
+	resolve := func(word string) string {
+		p := scriptWord(gd, word)
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(dir, p)
+		}
+		return filepath.Clean(p)
+	}
+
+	job := &runJob{
+		gd:   gd,
+		name: gd.Script,
+	}
+	if wd, err := os.Getwd(); err == nil {
+		if rel, err := filepath.Rel(wd, gd.Script); err == nil {
+			job.name = rel
+		}
+	}
+
+	outputs := map[string]bool{}
+	lines := []string{}
+	for _, line := range strings.Split(string(b), "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		lines = append(lines, line)
+
+		for _, m := range scriptOutputPattern.FindAllStringSubmatch(line, -1) {
+			p := resolve(m[1])
+			if !outputs[p] {
+				outputs[p] = true
+				job.outputs = append(job.outputs, p)
+			}
+		}
+	}
+
+	inputs := map[string]bool{}
+	for _, line := range lines {
+		for _, word := range scriptInputPattern.FindAllString(line, -1) {
+			p := resolve(word)
+			if !outputs[p] && !inputs[p] {
+				inputs[p] = true
+				job.inputs = append(job.inputs, p)
+			}
+		}
+	}
+	return job, nil
+}
+
+// dedupeJobs removes jobs running the same script with the same arguments in the same directory,
+// such as directives repeated in files of a package, because they write the same outputs.
+// Jobs whose inputs or outputs differ by variables such as $GOFILE are kept.
+func dedupeJobs(jobs []*runJob) []*runJob {
+	seen := map[string]bool{}
+	res := []*runJob{}
+	for _, job := range jobs {
+		dir, err := filepath.Abs(job.gd.Dir)
+		if err != nil {
+			dir = job.gd.Dir
+		}
+		words := append([]string{dir}, job.gd.Args...)
+		words = append(append(append(words, ""), job.inputs...), "")
+		words = append(words, job.outputs...)
+
+		key := strings.Join(words, "\x00")
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		res = append(res, job)
+	}
+	return res
+}
+
+// sortJobs links jobs to jobs writing their inputs and checks cycles.
+func sortJobs(jobs []*runJob) error {
+	writers := map[string]*runJob{}
+	for _, job := range jobs {
+		for _, output := range job.outputs {
+			if other, ok := writers[output]; ok {
+				return fmt.Errorf("%s is written by both %s and %s", output, other.name, job.name)
+			}
+			writers[output] = job
+		}
+	}
+
+	for _, job := range jobs {
+		deps := map[*runJob]bool{}
+		for _, input := range job.inputs {
+			writer, ok := writers[input]
+			if !ok || writer == job || deps[writer] {
+				continue
+			}
+			deps[writer] = true
+			writer.dependents = append(writer.dependents, job)
+			job.numDeps++
+		}
+	}
+
+	// Kahn's algorithm to detect cycles
+	numDeps := map[*runJob]int{}
+	queue := []*runJob{}
+	for _, job := range jobs {
+		numDeps[job] = job.numDeps
+		if job.numDeps == 0 {
+			queue = append(queue, job)
+		}
+	}
+	for i := 0; i < len(queue); i++ {
+		for _, d := range queue[i].dependents {
+			numDeps[d]--
+			if numDeps[d] == 0 {
+				queue = append(queue, d)
+			}
+		}
+	}
+	if len(queue) < len(jobs) {
+		names := []string{}
+		for _, job := range jobs {
+			if numDeps[job] > 0 {
+				names = append(names, job.name)
+			}
+		}
+		return fmt.Errorf("scripts depend on each other: %s", strings.Join(names, ", "))
+	}
+	return nil
+}
+
+// prefixWriter writes lines with the prefix. Lines from writers sharing the mutex are not mixed.
+type prefixWriter struct {
+	mu     *sync.Mutex
+	w      io.Writer
+	prefix string
+	buf    []byte
+}
+
+func (pw *prefixWriter) Write(b []byte) (int, error) {
+	pw.buf = append(pw.buf, b...)
+	i := bytes.LastIndexByte(pw.buf, '\n')
+	if i < 0 {
+		return len(b), nil
+	}
+
+	err := pw.writeLines(pw.buf[:i+1])
+	pw.buf = pw.buf[i+1:]
+	return len(b), err
+}
+
+// Flush writes the incomplete last line.
+func (pw *prefixWriter) Flush() error {
+	if len(pw.buf) == 0 {
+		return nil
+	}
+	err := pw.writeLines(append(pw.buf, '\n'))
+	pw.buf = nil
+	return err
+}
+
+func (pw *prefixWriter) writeLines(b []byte) error {
+	out := &bytes.Buffer{}
+	for _, line := range bytes.SplitAfter(b, []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		out.WriteString(pw.prefix)
+		out.Write(line)
+	}
+
+	pw.mu.Lock()
+	defer pw.mu.Unlock()
+	_, err := pw.w.Write(out.Bytes())
+	return err
+}
+
+// runJobs runs jobs in parallel after their dependencies.
+// Jobs depending on failed jobs are skipped.
+func runJobs(jobs []*runJob, numWorkers int, run func(*runJob) error) {
+	ready := make(chan *runJob, len(jobs))
+	done := make(chan *runJob)
+
+	wg := &sync.WaitGroup{}
+	for i := 0; i < numWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range ready {
+				job.err = run(job)
+				done <- job
+			}
+		}()
+	}
+
+	numDeps := map[*runJob]int{}
+	for _, job := range jobs {
+		numDeps[job] = job.numDeps
+		if job.numDeps == 0 {
+			ready <- job
+		}
+	}
+
+	skipped := []*runJob{}
+	for finished := 0; finished < len(jobs); finished++ {
+		var job *runJob
+		if len(skipped) > 0 {
+			job, skipped = skipped[0], skipped[1:]
+		} else {
+			job = <-done
+		}
+
+		for _, d := range job.dependents {
+			if job.err != nil || job.skipped {
+				d.skipped = true
+			}
+			numDeps[d]--
+			if numDeps[d] > 0 {
+				continue
+			}
+			if d.skipped {
+				skipped = append(skipped, d)
+			} else {
+				ready <- d
+			}
+		}
+	}
+
+	close(ready)
+	wg.Wait()
+}
+
+// runScript runs the script of the job.
+func runScript(job *runJob, out io.Writer, env ...string) error {
+	cmd, err := generateCommand(job.gd, env...)
+	if err != nil {
+		return err
+	}
+	cmd.Stdout = out
+	cmd.Stderr = out
+	return cmd.Run()
+}
+
+// runDependencies returns files affecting outputs of the script besides files recorded by commands:
+// the gogtok executable which scripts run and configuration files from the directory to the effective one.
+// Configuration files which do not exist are also returned to detect new ones.
+func runDependencies(gd *query.GenerateDirective) ([]string, error) {
+	res := []string{}
+	if exe, err := os.Executable(); err == nil {
+		res = append(res, exe)
+	}
+
+	dir, err := filepath.Abs(gd.Dir)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		filename := filepath.Join(dir, configFileName)
+		res = append(res, filename)
+		if _, err := os.Stat(filename); err == nil {
+			break
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+	return res, nil
+}
+
+// runJobIncrementally runs the script if the script or files recorded at the last run are changed.
+func runJobIncrementally(job *runJob, out io.Writer, cacheDir string) error {
+	cachePath, err := runCachePath(cacheDir, job.gd)
+	if err != nil {
+		return err
+	}
+
+	cache, err := loadRunCache(cachePath)
+	if err != nil {
+		return err
+	}
+	if ok, err := cache.isUpToDate(job.gd.Script); err != nil {
+		return err
+	} else if ok {
+		job.upToDate = true
+		return nil
+	}
+
+	manifest, err := ioutil.TempFile("", "gogtok-manifest")
+	if err != nil {
+		return err
+	}
+	manifest.Close()
+	defer os.Remove(manifest.Name())
+
+	if err := runScript(job, out, manifestEnv+"="+manifest.Name()); err != nil {
+		return err
+	}
+
+	inputs, err := readManifest(manifest.Name())
+	if err != nil {
+		return err
+	}
+	deps, err := runDependencies(job.gd)
+	if err != nil {
+		return err
+	}
+	inputs = append(append(inputs, job.inputs...), deps...)
+
+	cache = &runCache{}
+	if cache.Script, err = hashFile(job.gd.Script); err != nil {
+		return err
+	}
+	if cache.Inputs, err = hashFiles(inputs); err != nil {
+		return err
+	}
+	if cache.Outputs, err = hashFiles(job.outputs); err != nil {
+		return err
+	}
+	return saveRunCache(cachePath, cache)
+}
+
+func newRun(c *config) *cobra.Command {
+	numWorkers := runtime.NumCPU()
+	incremental := false
+	cacheDir := ""
+
+	cmd := &cobra.Command{
+		Use:   "run [packages...]",
+		Short: "Run scripts in //go:generate directives in parallel",
+		Long: `Run scripts created by "gogtok new" in //go:generate directives in parallel.
+
+Go files in a script are inputs, and files written by "gogtok write" or -o of gogtok commands are outputs.
+A script runs after scripts writing its inputs. Scripts depending on failed scripts are skipped.
+A script invoked with the same arguments by directives of a package runs once.
+Outputs of scripts are prefixed with their names.
+
+With --incremental, commands in scripts record files they read into a manifest,
+and scripts are skipped if the scripts, the recorded files, Go files in the scripts, the outputs,
+the gogtok executable and ` + configFileName + ` are not changed since the last run.`,
+		Example: `  gogtok run -j 4 ./...`,
+		RunE: func(_ *cobra.Command, args []string) error {
+			if numWorkers < 1 {
+				return errors.New("--jobs must be positive")
+			}
+
+			dirs, err := query.ExpandPatterns(args)
+			if err != nil {
+				return err
+			}
+
+			directives, err := query.GenerateDirectives(c.queryConfig(false), dirs...)
+			if err != nil {
+				return err
+			}
+
+			jobs := []*runJob{}
+			for _, gd := range directives {
+				if !gd.Gogtok {
+					continue
+				}
+				job, err := newRunJob(gd)
+				if err != nil {
+					return err
+				}
+				jobs = append(jobs, job)
+			}
+
+			jobs = dedupeJobs(jobs)
+			if err := sortJobs(jobs); err != nil {
+				return err
+			}
+
+			if incremental && cacheDir == "" {
+				userCacheDir, err := os.UserCacheDir()
+				if err != nil {
+					return err
+				}
+				cacheDir = filepath.Join(userCacheDir, "gogtok", "run")
+			}
+
+			mu := &sync.Mutex{}
+			runJobs(jobs, numWorkers, func(job *runJob) error {
+				out := &prefixWriter{mu: mu, w: os.Stdout, prefix: "[" + job.name + "] "}
+				defer out.Flush()
+
+				if incremental {
+					return runJobIncrementally(job, out, cacheDir)
+				}
+				return runScript(job, out)
+			})
+
+			numFailed, numSkipped, numUpToDate := 0, 0, 0
+			for _, job := range jobs {
+				switch {
+				case job.skipped:
+					numSkipped++
+				case job.err != nil:
+					numFailed++
+				case job.upToDate:
+					numUpToDate++
+				}
+			}
+
+			fmt.Fprintf(os.Stderr, "%d scripts succeeded, %d failed, %d skipped", len(jobs)-numFailed-numSkipped, numFailed, numSkipped)
+			if incremental {
+				fmt.Fprintf(os.Stderr, ", %d up to date", numUpToDate)
+			}
+			fmt.Fprintln(os.Stderr)
+			for _, job := range jobs {
+				switch {
+				case job.skipped:
+					fmt.Fprintf(os.Stderr, "  skipped %s (%s:%d)\n", job.name, job.gd.File, job.gd.Line)
+				case job.err != nil:
+					fmt.Fprintf(os.Stderr, "  failed %s (%s:%d): %v\n", job.name, job.gd.File, job.gd.Line, job.err)
+				}
+			}
+
+			if numFailed > 0 {
+				return errors.New(strconv.Itoa(numFailed) + " scripts failed")
+			}
+			return nil
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.IntVarP(&numWorkers, "jobs", "j", numWorkers, "Number of scripts to run in parallel")
+	flags.BoolVar(&incremental, "incremental", incremental, "Skip scripts whose inputs are not changed")
+	flags.StringVar(&cacheDir, "cache-dir", cacheDir, "Directory of hashes for --incremental (default is in the user cache directory)")
+
+	return cmd
+}
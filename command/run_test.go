@@ -0,0 +1,253 @@
+package command
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/utisam/gogtok/query"
+)
+
+// testJob returns a job with absolute paths of the inputs and outputs in the directory.
+func testJob(name, dir string, inputs, outputs []string) *runJob {
+	job := &runJob{
+		gd:   &query.GenerateDirective{Dir: dir, Args: []string{"./" + name}},
+		name: name,
+	}
+	for _, input := range inputs {
+		job.inputs = append(job.inputs, filepath.Join(dir, input))
+	}
+	for _, output := range outputs {
+		job.outputs = append(job.outputs, filepath.Join(dir, output))
+	}
+	return job
+}
+
+func jobNames(jobs []*runJob) []string {
+	res := []string{}
+	for _, job := range jobs {
+		res = append(res, job.name)
+	}
+	return res
+}
+
+func TestNewRunJob(t *testing.T) {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "gen.sh")
+	err := ioutil.WriteFile(script, []byte(`#!/bin/bash
+# gogtok write ignored.go
+render_file | gogtok fmt | gogtok write "gen_$GOFILE"
+gogtok enum Kind -o=kind_gen.go types.go
+gogtok list fields T 'other.go' kind_gen.go
+`), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	job, err := newRunJob(&query.GenerateDirective{Dir: dir, File: filepath.Join(dir, "a.go"), Script: script})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantOutputs := []string{filepath.Join(dir, "gen_a.go"), filepath.Join(dir, "kind_gen.go")}
+	if !reflect.DeepEqual(job.outputs, wantOutputs) {
+		t.Errorf("outputs = %v, want %v", job.outputs, wantOutputs)
+	}
+	wantInputs := []string{filepath.Join(dir, "types.go"), filepath.Join(dir, "other.go")}
+	if !reflect.DeepEqual(job.inputs, wantInputs) {
+		t.Errorf("inputs = %v, want %v", job.inputs, wantInputs)
+	}
+}
+
+func TestSortJobs(t *testing.T) {
+	dir := t.TempDir()
+
+	a := testJob("a", dir, []string{"types.go"}, []string{"a.go"})
+	b := testJob("b", dir, []string{"a.go", "types.go"}, []string{"b.go"})
+	c := testJob("c", dir, []string{"a.go", "b.go", "c.go"}, []string{"c.go"})
+	if err := sortJobs([]*runJob{c, b, a}); err != nil {
+		t.Fatal(err)
+	}
+	if got := jobNames(a.dependents); !reflect.DeepEqual(got, []string{"c", "b"}) {
+		t.Errorf("dependents of a = %v", got)
+	}
+	if got := jobNames(b.dependents); !reflect.DeepEqual(got, []string{"c"}) {
+		t.Errorf("dependents of b = %v", got)
+	}
+	if a.numDeps != 0 || b.numDeps != 1 || c.numDeps != 2 {
+		t.Errorf("numDeps = %d, %d, %d", a.numDeps, b.numDeps, c.numDeps)
+	}
+
+	err := sortJobs([]*runJob{
+		testJob("x", dir, []string{"z.go"}, []string{"x.go"}),
+		testJob("y", dir, []string{"x.go"}, []string{"y.go"}),
+		testJob("z", dir, []string{"y.go"}, []string{"z.go"}),
+		testJob("w", dir, []string{"z.go"}, []string{"w.go"}),
+	})
+	if err == nil || err.Error() != "scripts depend on each other: x, y, z, w" {
+		t.Errorf("cycles must be detected: %v", err)
+	}
+
+	err = sortJobs([]*runJob{
+		testJob("x", dir, nil, []string{"x.go"}),
+		testJob("y", dir, nil, []string{"x.go"}),
+	})
+	if err == nil || !strings.Contains(err.Error(), "written by both x and y") {
+		t.Errorf("conflicting outputs must be detected: %v", err)
+	}
+}
+
+func TestDedupeJobs(t *testing.T) {
+	dir := t.TempDir()
+
+	jobs := []*runJob{
+		testJob("a", dir, []string{"types.go"}, []string{"a.go"}),
+		testJob("a", dir, []string{"types.go"}, []string{"a.go"}),
+		testJob("a", dir, []string{"types.go"}, []string{"a2.go"}),
+		testJob("a", filepath.Join(dir, "sub"), []string{"types.go"}, []string{"a.go"}),
+		testJob("b", dir, []string{"types.go"}, []string{"b.go"}),
+	}
+	if got := dedupeJobs(jobs); !reflect.DeepEqual(got, []*runJob{jobs[0], jobs[2], jobs[3], jobs[4]}) {
+		t.Errorf("dedupeJobs() = %v", jobNames(got))
+	}
+}
+
+func TestRunJobs(t *testing.T) {
+	dir := t.TempDir()
+
+	a := testJob("a", dir, nil, []string{"a.go"})
+	b := testJob("b", dir, []string{"a.go"}, []string{"b.go"})
+	c := testJob("c", dir, nil, []string{"c.go"})
+	d := testJob("d", dir, []string{"c.go"}, []string{"d.go"})
+	e := testJob("e", dir, []string{"d.go"}, []string{"e.go"})
+	jobs := []*runJob{e, d, c, b, a}
+	if err := sortJobs(jobs); err != nil {
+		t.Fatal(err)
+	}
+
+	mu := &sync.Mutex{}
+	order := []string{}
+	runJobs(jobs, 3, func(job *runJob) error {
+		mu.Lock()
+		defer mu.Unlock()
+		order = append(order, job.name)
+		if job == c {
+			return errors.New("failed")
+		}
+		return nil
+	})
+
+	if len(order) != 3 || strings.Index(strings.Join(order, ""), "a") > strings.Index(strings.Join(order, ""), "b") {
+		t.Errorf("jobs run in %v", order)
+	}
+	if c.err == nil || !d.skipped || !e.skipped || a.skipped || b.skipped || b.err != nil {
+		t.Errorf("jobs depending on failed jobs must be skipped")
+	}
+}
+
+func TestRun(t *testing.T) {
+	dir := newTestModule(t, map[string]string{
+		"a.go": "package test\n\n//go:generate ./gen.sh\n",
+		"b.go": "package test\n\n//go:generate ./gen.sh\n//go:generate ./dep.sh\n",
+		"gen.sh": `#!/bin/sh
+gogtok() { cat > "$2"; }
+echo gen >> runs.log
+printf "// Code generated by './gen.sh'. DO NOT EDIT.\npackage test\n" | gogtok write gen.go
+`,
+		"dep.sh": `#!/bin/sh
+gogtok() { cat > "$2"; }
+echo dep >> runs.log
+test -f gen.go || exit 1
+printf "// Code generated by './dep.sh'. DO NOT EDIT.\npackage test\n" | gogtok write dep_gen.go
+`,
+	})
+	for _, name := range []string{"gen.sh", "dep.sh"} {
+		if err := os.Chmod(filepath.Join(dir, name), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// gen.sh invoked by two directives runs once before dep.sh reading its output
+	if err := runGogtok("run", "-j", "4", dir); err != nil {
+		t.Fatal(err)
+	}
+	b, err := ioutil.ReadFile(filepath.Join(dir, "runs.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "gen\ndep\n" {
+		t.Errorf("scripts run:\n%s", b)
+	}
+}
+
+func TestRunIncremental(t *testing.T) {
+	depScript := `#!/bin/sh
+gogtok() { cat > "$2"; }
+echo dep >> runs.log
+test -f gen.go || exit 1
+printf "// Code generated by './dep.sh'. DO NOT EDIT.\npackage test\n" | gogtok write dep_gen.go
+`
+	dir := newTestModule(t, map[string]string{
+		"a.go":     "package test\n\n//go:generate ./gen.sh\n//go:generate ./dep.sh\n",
+		"data.txt": "data",
+		"gen.sh": `#!/bin/sh
+gogtok() { cat > "$2"; }
+echo gen >> runs.log
+echo "$PWD/data.txt" >> "$GOGTOK_MANIFEST"
+printf "// Code generated by './gen.sh'. DO NOT EDIT.\npackage test\n" | gogtok write gen.go
+`,
+		"dep.sh": depScript,
+	})
+	for _, name := range []string{"gen.sh", "dep.sh"} {
+		if err := os.Chmod(filepath.Join(dir, name), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cacheDir := t.TempDir()
+
+	writeFile := func(name, content string) {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	run := func(want string) {
+		t.Helper()
+		writeFile("runs.log", "")
+		if err := runGogtok("run", "--incremental", "--cache-dir", cacheDir, dir); err != nil {
+			t.Fatal(err)
+		}
+		b, err := ioutil.ReadFile(filepath.Join(dir, "runs.log"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := strings.Join(strings.Fields(string(b)), " "); got != want {
+			t.Errorf("scripts run: %q, want %q", got, want)
+		}
+	}
+
+	run("gen dep")
+	run("")
+
+	// A file recorded in the manifest
+	writeFile("data.txt", "changed")
+	run("gen")
+
+	// An output of the script which is an input of another script
+	writeFile("gen.go", "package test\n")
+	run("gen")
+
+	// An output of the script
+	if err := os.Remove(filepath.Join(dir, "dep_gen.go")); err != nil {
+		t.Fatal(err)
+	}
+	run("dep")
+
+	// The script
+	writeFile("dep.sh", depScript+"# changed\n")
+	run("dep")
+	run("")
+}
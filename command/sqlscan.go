@@ -0,0 +1,173 @@
+package command
+
+import (
+	"fmt"
+	"go/types"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+type sqlScanColumn struct {
+	Name string
+	// Field is the selector of the field such as "Base.ID".
+	Field string
+}
+
+type sqlScanTemplateData struct {
+	Type    string
+	Const   string
+	Columns []*sqlScanColumn
+}
+
+// Names returns names of the columns.
+func (d *sqlScanTemplateData) Names() []string {
+	res := make([]string, len(d.Columns))
+	for i, c := range d.Columns {
+		res[i] = c.Name
+	}
+	return res
+}
+
+const sqlScanTemplate = `{{$t := .Type -}}
+// {{.Const}} is the list of columns of {{$t}} in the order of ScanRow and Values.
+const {{.Const}} = {{quote (join .Names ", ")}}
+
+// ScanRow scans the row of {{.Const}} into x.
+func (x *{{$t}}) ScanRow(row interface{ Scan(dest ...interface{}) error }) error {
+	return row.Scan(
+{{- range .Columns}}
+		&x.{{.Field}},
+{{- end}}
+	)
+}
+
+// Values returns values of {{.Const}} to insert or update.
+func (x *{{$t}}) Values() []interface{} {
+	return []interface{}{
+{{- range .Columns}}
+		x.{{.Field}},
+{{- end}}
+	}
+}
+`
+
+// sqlScanner reports whether the type implements sql.Scanner like sql.NullString.
+func sqlScanner(t types.Type) bool {
+	obj, _, _ := types.LookupFieldOrMethod(types.NewPointer(t), true, nil, "Scan")
+	_, ok := obj.(*types.Func)
+	return ok
+}
+
+// sqlScanColumns collects columns from fields of the struct.
+// Fields of embedded structs without tags are flattened.
+func sqlScanColumns(pkg *types.Package, st *types.Struct, prefix, tagKey string, all bool) ([]*sqlScanColumn, error) {
+	res := []*sqlScanColumn{}
+	for i := 0; i < st.NumFields(); i++ {
+		f := st.Field(i)
+		if f.Name() == "_" || (!f.Exported() && f.Pkg() != pkg) {
+			continue
+		}
+
+		tag := fieldTag(st, i, tagKey)
+		name := strings.SplitN(tag, ",", 2)[0]
+		if name == "-" {
+			continue
+		}
+
+		if name == "" && f.Anonymous() {
+			if _, ok := f.Type().Underlying().(*types.Pointer); ok {
+				return nil, fmt.Errorf("embedded pointer is not supported: %s", f.Name())
+			}
+			if embedded, ok := f.Type().Underlying().(*types.Struct); ok && !sqlScanner(f.Type()) {
+				columns, err := sqlScanColumns(pkg, embedded, prefix+f.Name()+".", tagKey, all)
+				if err != nil {
+					return nil, err
+				}
+				res = append(res, columns...)
+				continue
+			}
+		}
+
+		if name == "" {
+			if !all || !f.Exported() {
+				continue
+			}
+			name = snakeCase(f.Name())
+		}
+		res = append(res, &sqlScanColumn{
+			Name:  name,
+			Field: prefix + f.Name(),
+		})
+	}
+	return res, nil
+}
+
+func newSQLScan(c *config) *cobra.Command {
+	opts := &generatorOptions{config: c}
+	tagKey := "db"
+	all := false
+
+	cmd := &cobra.Command{
+		Use:   "sqlscan TYPE [package]",
+		Short: "Generate the list of columns, ScanRow and Values of the struct from tags",
+		Long: `Generate the list of columns, ScanRow and Values of the struct from tags.
+
+Columns are named by tags such as db:"name", and fields tagged with db:"-" are skipped.
+<TYPE>Columns is the list of columns joined by commas for SELECT and INSERT.
+ScanRow scans *sql.Row or *sql.Rows into fields, and Values returns fields in the same order.
+Fields of embedded structs without tags are flattened unless they implement sql.Scanner like sql.NullString.
+With --all, untagged exported fields are also columns named in snake case.`,
+		Example: `  gogtok sqlscan User -o user_sql.go`,
+		Args:    cobra.RangeArgs(1, 2),
+		RunE: func(_ *cobra.Command, args []string) error {
+			typeName := args[0]
+			dir := "."
+			if len(args) > 1 {
+				dir = args[1]
+			}
+
+			pkg, obj, err := opts.lookupType(dir, typeName)
+			if err != nil {
+				return err
+			}
+			st, ok := obj.Type().Underlying().(*types.Struct)
+			if !ok {
+				return fmt.Errorf("not a struct: %s", typeName)
+			}
+
+			columns, err := sqlScanColumns(pkg.Types, st, "", tagKey, all)
+			if err != nil {
+				return err
+			}
+			if len(columns) == 0 {
+				return fmt.Errorf("no columns: %s", typeName)
+			}
+			seen := map[string]string{}
+			for _, col := range columns {
+				if other, ok := seen[col.Name]; ok {
+					return fmt.Errorf("%s and %s have the same column: %s", other, col.Field, col.Name)
+				}
+				seen[col.Name] = col.Field
+			}
+
+			data := &sqlScanTemplateData{
+				Type:    typeName,
+				Const:   typeName + "Columns",
+				Columns: columns,
+			}
+			body, err := executeTemplate(sqlScanTemplate, data)
+			if err != nil {
+				return err
+			}
+			return opts.write(dir, pkg.Types.Name(), body)
+		},
+	}
+
+	opts.addFlags(cmd)
+	flags := cmd.Flags()
+	flags.StringVar(&tagKey, "tag", tagKey, "Key of the tag of column names")
+	flags.BoolVar(&all, "all", all, "Use untagged exported fields as columns named in snake case")
+
+	return cmd
+}
@@ -0,0 +1,103 @@
+package command
+
+import "testing"
+
+func TestSQLScan(t *testing.T) {
+	dir := newTestModule(t, map[string]string{
+		"user.go": `package test
+
+import "database/sql"
+
+type Base struct {
+	ID int64 ` + "`db:\"id\"`" + `
+}
+
+type User struct {
+	Base
+	Name     string         ` + "`db:\"name\"`" + `
+	Nickname sql.NullString ` + "`db:\"nickname\"`" + `
+	Password string         ` + "`db:\"-\"`" + `
+	Age      int
+}
+`,
+		"user_test.go": `package test
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+)
+
+type row []interface{}
+
+func (r row) Scan(dest ...interface{}) error {
+	for i, d := range dest {
+		if s, ok := d.(sql.Scanner); ok {
+			if err := s.Scan(r[i]); err != nil {
+				return err
+			}
+			continue
+		}
+		reflect.ValueOf(d).Elem().Set(reflect.ValueOf(r[i]))
+	}
+	return nil
+}
+
+func TestUser(t *testing.T) {
+	if UserColumns != "id, name, nickname, age" {
+		t.Errorf("UserColumns = %s", UserColumns)
+	}
+
+	var u User
+	if err := u.ScanRow(row{int64(1), "name", "nick", 20}); err != nil {
+		t.Fatal(err)
+	}
+	want := User{Base: Base{ID: 1}, Name: "name", Nickname: sql.NullString{String: "nick", Valid: true}, Age: 20}
+	if u != want {
+		t.Errorf("ScanRow() = %+v", u)
+	}
+	if v := u.Values(); !reflect.DeepEqual(v, []interface{}{int64(1), "name", want.Nickname, 20}) {
+		t.Errorf("Values() = %v", v)
+	}
+}
+`,
+	})
+
+	// Generating over the previous output replaces it
+	for i := 0; i < 2; i++ {
+		generate(t, dir, "user_gen.go", "sqlscan", "User", "--all")
+	}
+	goTest(t, dir)
+}
+
+func TestSQLScanErrors(t *testing.T) {
+	dir := newTestModule(t, map[string]string{
+		"user.go": `package test
+
+type Base struct {
+	ID int64 ` + "`db:\"id\"`" + `
+}
+
+type Duplicate struct {
+	Base
+	UserID int64 ` + "`db:\"id\"`" + `
+}
+
+type Pointer struct {
+	*Base
+}
+
+type Untagged struct {
+	Name string
+	age  int ` + "`db:\"age\"`" + `
+}
+
+type Names []string
+`,
+	})
+
+	generateError(t, dir, "Base.ID and UserID have the same column: id", "sqlscan", "Duplicate")
+	generateError(t, dir, "embedded pointer is not supported: Base", "sqlscan", "Pointer")
+	generateError(t, dir, "no columns: Untagged", "sqlscan", "Untagged", "--tag", "sql")
+	generateError(t, dir, "not a struct: Names", "sqlscan", "Names")
+}
@@ -0,0 +1,539 @@
+package command
+
+import (
+	"fmt"
+	"go/types"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/utisam/gogtok/query"
+)
+
+// validateMethod is how values of a named type are validated.
+type validateMethod int
+
+const (
+	validateNone validateMethod = iota
+	// validateInternal calls validateFields(prefix string, errs *[]string) generated by this command.
+	validateInternal
+	// validateError calls Validate() error.
+	validateError
+)
+
+// validateRule is a rule in the tag such as "min=1".
+type validateRule struct {
+	Name  string
+	Value string
+}
+
+// parseValidateTag parses rules in the tag such as "required,max=10".
+// regexp must be the last because the pattern may contain commas.
+func parseValidateTag(tag string) ([]*validateRule, error) {
+	rules := []*validateRule{}
+	for tag != "" {
+		item := tag
+		if !strings.HasPrefix(tag, "regexp=") {
+			tag = ""
+			if i := strings.IndexByte(item, ','); i >= 0 {
+				item, tag = item[:i], item[i+1:]
+			}
+		} else {
+			tag = ""
+		}
+
+		rule := &validateRule{Name: item}
+		if i := strings.IndexByte(item, '='); i >= 0 {
+			rule.Name, rule.Value = item[:i], item[i+1:]
+		}
+		switch rule.Name {
+		case "required":
+		case "min", "max", "len", "oneof", "regexp":
+			if rule.Value == "" {
+				return nil, fmt.Errorf("%s requires a value", rule.Name)
+			}
+		default:
+			return nil, fmt.Errorf("unknown rule: %s", rule.Name)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// pathExpr appends the string to the expression of the path.
+func pathExpr(expr, s string) string {
+	quoted := strconv.Quote(s)
+	if strings.HasSuffix(expr, `"`) {
+		return expr[:len(expr)-1] + quoted[1:]
+	}
+	return expr + " + " + quoted
+}
+
+type validatePattern struct {
+	Name    string
+	Pattern string
+}
+
+// validateGenerator generates Validate of the type
+// and named structs in the same package reached from it.
+type validateGenerator struct {
+	pkg  *query.Package
+	opts *generatorOptions
+	// typeName is the name of the type specified in the command line.
+	typeName string
+	imports  *generatorImports
+	tagKey   string
+	queue    []*types.Named
+	queued   map[*types.Named]bool
+	numVars  int
+	patterns []*validatePattern
+}
+
+// existingMethod returns the method of the named type unless it is declared in files to be replaced.
+func (g *validateGenerator) existingMethod(named *types.Named, name string) *types.Signature {
+	obj, _, _ := types.LookupFieldOrMethod(types.NewPointer(named), true, named.Obj().Pkg(), name)
+	fn, ok := obj.(*types.Func)
+	if !ok || g.opts.replaces(g.pkg, fn, g.typeName) {
+		return nil
+	}
+	return fn.Type().(*types.Signature)
+}
+
+func (g *validateGenerator) existing(named *types.Named) validateMethod {
+	if named.Obj().Pkg() == g.pkg.Types && g.existingMethod(named, "validateFields") != nil {
+		return validateInternal
+	}
+	if sig := g.existingMethod(named, "Validate"); sig != nil && sig.Params().Len() == 0 && sig.Results().Len() == 1 {
+		if types.Identical(sig.Results().At(0).Type(), types.Universe.Lookup("error").Type()) {
+			return validateError
+		}
+	}
+	return validateNone
+}
+
+// generated reports whether Validate of the named type is generated.
+func (g *validateGenerator) generated(named *types.Named) bool {
+	if named.Obj().Pkg() != g.pkg.Types || g.existingMethod(named, "Validate") != nil || g.existingMethod(named, "validateFields") != nil {
+		return false
+	}
+	if _, ok := named.Underlying().(*types.Struct); !ok {
+		return false
+	}
+	return g.hasRules(named, map[*types.Named]bool{})
+}
+
+func (g *validateGenerator) method(named *types.Named) validateMethod {
+	if m := g.existing(named); m != validateNone {
+		return m
+	}
+	if g.generated(named) {
+		return validateInternal
+	}
+	return validateNone
+}
+
+// hasRules reports whether values of the type have fields to be validated.
+func (g *validateGenerator) hasRules(t types.Type, visited map[*types.Named]bool) bool {
+	if named, ok := t.(*types.Named); ok {
+		if g.existing(named) != validateNone {
+			return true
+		}
+		if visited[named] || named.Obj().Pkg() != g.pkg.Types {
+			return false
+		}
+		visited[named] = true
+	}
+
+	switch u := t.Underlying().(type) {
+	case *types.Pointer:
+		return g.hasRules(u.Elem(), visited)
+	case *types.Slice:
+		return g.hasRules(u.Elem(), visited)
+	case *types.Array:
+		return g.hasRules(u.Elem(), visited)
+	case *types.Map:
+		return g.hasRules(u.Elem(), visited)
+	case *types.Struct:
+		for i := 0; i < u.NumFields(); i++ {
+			tag := fieldTag(u, i, g.tagKey)
+			if tag == "-" {
+				continue
+			}
+			if tag != "" || g.hasRules(u.Field(i).Type(), visited) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (g *validateGenerator) enqueue(named *types.Named) {
+	if g.generated(named) && !g.queued[named] {
+		g.queued[named] = true
+		g.queue = append(g.queue, named)
+	}
+}
+
+func (g *validateGenerator) newVar(prefix string) string {
+	g.numVars++
+	return fmt.Sprint(prefix, g.numVars)
+}
+
+func appendError(b *strings.Builder, path, msg string) {
+	fmt.Fprintf(b, "*errs = append(*errs, %s)\n", pathExpr(path, msg))
+}
+
+// validateValue writes statements validating nested values of the type.
+// name is the name of the field used in names of patterns and errors of generation.
+func (g *validateGenerator) validateValue(b *strings.Builder, typeName, name, path, x string, t types.Type) error {
+	if !g.hasRules(t, map[*types.Named]bool{}) {
+		return nil
+	}
+
+	if named, ok := t.(*types.Named); ok {
+		g.enqueue(named)
+		switch g.method(named) {
+		case validateInternal:
+			fmt.Fprintf(b, "%s.validateFields(%s, errs)\n", operand(x), pathExpr(path, "."))
+			return nil
+		case validateError:
+			fmt.Fprintf(b, "if err := %s.Validate(); err != nil {\n", operand(x))
+			fmt.Fprintf(b, "*errs = append(*errs, %s+err.Error())\n", pathExpr(path, ": "))
+			b.WriteString("}\n")
+			return nil
+		}
+	}
+
+	switch u := t.Underlying().(type) {
+	case *types.Pointer:
+		fmt.Fprintf(b, "if %s != nil {\n", x)
+		if err := g.validateValue(b, typeName, name, path, x, u.Elem()); err != nil {
+			return err
+		}
+		b.WriteString("}\n")
+
+	case *types.Slice, *types.Array:
+		elem := u.(interface{ Elem() types.Type }).Elem()
+		i := g.newVar("i")
+		fmt.Fprintf(b, "for %s := range %s {\n", i, x)
+		if err := g.validateValue(b, typeName, name, pathExpr(path, "[")+" + strconv.Itoa("+i+`) + "]"`, operand(x)+"["+i+"]", elem); err != nil {
+			return err
+		}
+		b.WriteString("}\n")
+
+	case *types.Map:
+		k, v := g.newVar("k"), g.newVar("v")
+		fmt.Fprintf(b, "for %s, %s := range %s {\n", k, v, x)
+		if err := g.validateValue(b, typeName, name, pathExpr(path, "[")+" + fmt.Sprint("+k+`) + "]"`, v, u.Elem()); err != nil {
+			return err
+		}
+		b.WriteString("}\n")
+
+	case *types.Struct:
+		// Fields of anonymous structs are validated inline
+		return g.validateFields(b, typeName, name+".", pathExpr(path, "."), operand(x)+".", u)
+	}
+	return nil
+}
+
+// lengthKind reports whether len is applicable to the type.
+func lengthKind(t types.Type) bool {
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		return u.Info()&types.IsString != 0
+	case *types.Slice, *types.Array, *types.Map, *types.Chan:
+		return true
+	}
+	return false
+}
+
+// literal checks the value in the tag is a literal of the type.
+func literal(t types.Type, value string) (string, error) {
+	basic, ok := t.Underlying().(*types.Basic)
+	switch {
+	case ok && basic.Info()&types.IsString != 0:
+		return strconv.Quote(value), nil
+	case ok && basic.Info()&types.IsInteger != 0:
+		if _, err := strconv.ParseInt(value, 0, 64); err != nil {
+			return "", fmt.Errorf("not an integer: %s", value)
+		}
+		return value, nil
+	case ok && basic.Info()&types.IsFloat != 0:
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return "", fmt.Errorf("not a number: %s", value)
+		}
+		return value, nil
+	}
+	return "", fmt.Errorf("unsupported type: %s", t)
+}
+
+// validateRule writes statements validating the rule of the field.
+func (g *validateGenerator) validateRule(b *strings.Builder, typeName, name, path, x string, t types.Type, rule *validateRule) error {
+	switch rule.Name {
+	case "required":
+		cond, err := zeroCondition(x, t, g.imports.qualifier)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(b, "if %s {\n", cond)
+		appendError(b, path, " is required")
+		b.WriteString("}\n")
+
+	case "min", "max", "len":
+		op, desc := "!=", ""
+		switch rule.Name {
+		case "min":
+			op, desc = "<", "at least "
+		case "max":
+			op, desc = ">", "at most "
+		}
+
+		if lengthKind(t) {
+			if _, err := strconv.Atoi(rule.Value); err != nil {
+				return fmt.Errorf("not a length: %s", rule.Value)
+			}
+			fmt.Fprintf(b, "if len(%s) %s %s {\n", x, op, rule.Value)
+			appendError(b, path, " must have length "+desc+rule.Value)
+		} else {
+			if rule.Name == "len" {
+				return fmt.Errorf("len is not applicable to %s", t)
+			}
+			v, err := literal(t, rule.Value)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(b, "if %s %s %s {\n", x, op, v)
+			appendError(b, path, " must be "+desc+rule.Value)
+		}
+		b.WriteString("}\n")
+
+	case "oneof":
+		values := strings.Fields(rule.Value)
+		lits := make([]string, len(values))
+		for i, value := range values {
+			v, err := literal(t, value)
+			if err != nil {
+				return err
+			}
+			lits[i] = v
+		}
+		fmt.Fprintf(b, "switch %s {\ncase %s:\ndefault:\n", x, strings.Join(lits, ", "))
+		appendError(b, path, " must be one of "+strings.Join(values, ", "))
+		b.WriteString("}\n")
+
+	case "regexp":
+		basic, ok := t.Underlying().(*types.Basic)
+		if !ok || basic.Info()&types.IsString == 0 {
+			return fmt.Errorf("regexp is not applicable to %s", t)
+		}
+		if _, err := regexp.Compile(rule.Value); err != nil {
+			return err
+		}
+		pattern := &validatePattern{
+			Name:    "validate" + upperFirst(typeName) + patternName(name) + "Pattern",
+			Pattern: strconv.Quote(rule.Value),
+		}
+		g.patterns = append(g.patterns, pattern)
+		conv := x
+		if _, ok := t.(*types.Named); ok {
+			conv = "string(" + x + ")"
+		}
+		fmt.Fprintf(b, "if !%s.MatchString(%s) {\n", pattern.Name, conv)
+		appendError(b, path, " must match "+rule.Value)
+		b.WriteString("}\n")
+	}
+	return nil
+}
+
+// patternName returns the name of the field in the name of the pattern.
+func patternName(name string) string {
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		parts[i] = upperFirst(part)
+	}
+	return strings.Join(parts, "")
+}
+
+// validateFields writes statements validating fields of the struct.
+// Names, paths and operands of fields are prefixed with namePrefix, pathPrefix and xPrefix.
+func (g *validateGenerator) validateFields(b *strings.Builder, typeName, namePrefix, pathPrefix, xPrefix string, st *types.Struct) error {
+	for i := 0; i < st.NumFields(); i++ {
+		f := st.Field(i)
+		tag := fieldTag(st, i, g.tagKey)
+		if tag == "-" || f.Name() == "_" {
+			continue
+		}
+
+		name, path := namePrefix+f.Name(), pathExpr(pathPrefix, f.Name())
+		rules, err := parseValidateTag(tag)
+		if err != nil {
+			return fmt.Errorf("%s.%s: %v", typeName, name, err)
+		}
+
+		x, t := xPrefix+f.Name(), f.Type()
+		checks := &strings.Builder{}
+		for _, rule := range rules {
+			if rule.Name == "required" {
+				continue
+			}
+			elemX, elemT := x, t
+			if ptr, ok := t.Underlying().(*types.Pointer); ok {
+				elemX, elemT = "*"+x, ptr.Elem()
+			}
+			if err := g.validateRule(checks, typeName, name, path, elemX, elemT, rule); err != nil {
+				return fmt.Errorf("%s.%s: %v", typeName, name, err)
+			}
+		}
+
+		for _, rule := range rules {
+			if rule.Name == "required" {
+				if err := g.validateRule(b, typeName, name, path, x, t, rule); err != nil {
+					return fmt.Errorf("%s.%s: %v", typeName, name, err)
+				}
+			}
+		}
+		if checks.Len() > 0 {
+			if _, ok := t.Underlying().(*types.Pointer); ok {
+				fmt.Fprintf(b, "if %s != nil {\n%s}\n", x, checks)
+			} else {
+				b.WriteString(checks.String())
+			}
+		}
+		if err := g.validateValue(b, typeName, name, path, x, t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type validateTemplateData struct {
+	Type string
+	Body string
+}
+
+const validateTemplate = `
+// Validate returns an error describing fields which violate rules in tags.
+func (x *{{.Type}}) Validate() error {
+	var errs []string
+	x.validateFields("", &errs)
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// validateFields appends errors of fields whose paths are prefixed.
+func (x *{{.Type}}) validateFields(prefix string, errs *[]string) {
+{{.Body -}}
+}
+`
+
+const validatePatternsTemplate = `
+var (
+{{- range .}}
+	{{.Name}} = regexp.MustCompile({{.Pattern}})
+{{- end}}
+)
+`
+
+func newValidate(c *config) *cobra.Command {
+	opts := &generatorOptions{config: c}
+	tagKey := "validate"
+
+	cmd := &cobra.Command{
+		Use:   "validate TYPE [package]",
+		Short: "Generate Validate method of the struct from tags",
+		Long: `Generate Validate method of the struct from tags.
+
+Rules are written in the tag separated by commas:
+  required          The field is not the zero value (or nil pointer)
+  min=N, max=N      The number or the length is at least or at most N
+  len=N             The length is N
+  oneof=A B C       The value is one of space separated values
+  regexp=PATTERN    The string matches the pattern. It must be the last rule
+Rules of pointers are applied to pointees if they are not nil.
+Nested structs in fields, pointers, slices, arrays and maps are validated recursively.
+Fields of anonymous structs are validated inline.
+Existing Validate() error methods of types are called, even if they are in other packages.
+Errors are aggregated with paths of fields such as "Items[0].Name is required".`,
+		Example: `  gogtok validate User -o user_validate.go`,
+		Args:    cobra.RangeArgs(1, 2),
+		RunE: func(_ *cobra.Command, args []string) error {
+			typeName := args[0]
+			dir := "."
+			if len(args) > 1 {
+				dir = args[1]
+			}
+
+			pkg, obj, err := opts.lookupType(dir, typeName)
+			if err != nil {
+				return err
+			}
+			named, ok := obj.Type().(*types.Named)
+			if !ok {
+				return fmt.Errorf("not a named type: %s", typeName)
+			}
+			if _, ok := named.Underlying().(*types.Struct); !ok {
+				return fmt.Errorf("not a struct: %s", typeName)
+			}
+
+			g := &validateGenerator{
+				pkg:      pkg,
+				opts:     opts,
+				typeName: typeName,
+				imports:  newGeneratorImports(pkg.Types.Path()),
+				tagKey:   tagKey,
+				queued:   map[*types.Named]bool{},
+			}
+			if g.existingMethod(named, "Validate") != nil {
+				return fmt.Errorf("%s already has Validate", typeName)
+			}
+			if !g.generated(named) {
+				return fmt.Errorf("%s has no rules", typeName)
+			}
+			g.enqueue(named)
+
+			b := &strings.Builder{}
+			for len(g.queue) > 0 {
+				t := g.queue[0]
+				g.queue = g.queue[1:]
+
+				g.numVars = 0
+				body := &strings.Builder{}
+				if err := g.validateFields(body, t.Obj().Name(), "", "prefix", "x.", t.Underlying().(*types.Struct)); err != nil {
+					return err
+				}
+
+				src, err := executeTemplate(validateTemplate, &validateTemplateData{
+					Type: t.Obj().Name(),
+					Body: body.String(),
+				})
+				if err != nil {
+					return err
+				}
+				b.Write(src)
+			}
+
+			if len(g.patterns) > 0 {
+				src, err := executeTemplate(validatePatternsTemplate, g.patterns)
+				if err != nil {
+					return err
+				}
+				b.Write(src)
+			}
+
+			imports := g.imports.imports
+			for _, path := range []string{"errors", "fmt", "regexp", "strconv", "strings"} {
+				imports = append(imports, &query.Import{Path: path})
+			}
+			return opts.write(dir, pkg.Types.Name(), []byte(b.String()), imports...)
+		},
+	}
+
+	opts.addFlags(cmd)
+	flags := cmd.Flags()
+	flags.StringVar(&tagKey, "tag", tagKey, "Key of the tag of rules")
+
+	return cmd
+}
@@ -0,0 +1,76 @@
+package command
+
+import "testing"
+
+func TestValidate(t *testing.T) {
+	dir := newTestModule(t, map[string]string{
+		"order.go": `package test
+
+type Order struct {
+	ID    string  ` + "`validate:\"required,regexp=^o[0-9]+$\"`" + `
+	Items []*Item ` + "`validate:\"min=1\"`" + `
+	Note  *string ` + "`validate:\"max=4\"`" + `
+	Anon  struct {
+		X string ` + "`validate:\"required\"`" + `
+	}
+	Lines []struct {
+		Qty int ` + "`validate:\"min=1\"`" + `
+	}
+}
+
+type Item struct {
+	Name   string ` + "`validate:\"oneof=apple banana\"`" + `
+	Weight Weight
+}
+
+type Weight float64
+
+func (w Weight) Validate() error {
+	if w < 0 {
+		return errNegative
+	}
+	return nil
+}
+`,
+		"errors.go": `package test
+
+import "errors"
+
+var errNegative = errors.New("negative")
+`,
+		"order_test.go": `package test
+
+import "testing"
+
+func TestValidate(t *testing.T) {
+	note := "too long"
+	o := &Order{
+		ID:    "x",
+		Items: []*Item{{Name: "apple"}, {Name: "cherry", Weight: -1}},
+		Note:  &note,
+	}
+	o.Lines = append(o.Lines, struct {
+		Qty int ` + "`validate:\"min=1\"`" + `
+	}{})
+
+	want := "ID must match ^o[0-9]+$; Items[1].Name must be one of apple, banana; Items[1].Weight: negative; " +
+		"Note must have length at most 4; Anon.X is required; Lines[0].Qty must be at least 1"
+	if err := o.Validate(); err == nil || err.Error() != want {
+		t.Errorf("Validate() = %v", err)
+	}
+
+	o = &Order{ID: "o1", Items: []*Item{{Name: "banana"}}}
+	o.Anon.X = "x"
+	if err := o.Validate(); err != nil {
+		t.Errorf("Validate() = %v", err)
+	}
+}
+`,
+	})
+
+	// Previous outputs written through stdout must be replaced
+	for i := 0; i < 2; i++ {
+		generateStdout(t, dir, "order_gen.go", "validate", "Order")
+	}
+	goTest(t, dir)
+}
@@ -0,0 +1,159 @@
+package command
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/utisam/gogtok/query"
+)
+
+// generateCommand creates a command to run the directive like "go generate".
+// The directory of the running gogtok is prepended to PATH so that scripts use the same version.
+func generateCommand(gd *query.GenerateDirective, env ...string) (*exec.Cmd, error) {
+	dir, err := filepath.Abs(gd.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command(gd.Args[0], gd.Args[1:]...)
+	cmd.Dir = dir
+	if strings.ContainsRune(gd.Args[0], '/') && !filepath.IsAbs(gd.Args[0]) {
+		cmd.Path = filepath.Join(dir, gd.Args[0])
+	}
+
+	cmd.Env = gd.Environ()
+	if exe, err := os.Executable(); err == nil {
+		cmd.Env = append(cmd.Env, "PATH="+filepath.Dir(exe)+string(filepath.ListSeparator)+os.Getenv("PATH"))
+	}
+	cmd.Env = append(cmd.Env, env...)
+	return cmd, nil
+}
+
+// writesOutputs reports whether the script writes outputs with "gogtok write" or -o of gogtok commands.
+func writesOutputs(script string) (bool, error) {
+	b, err := ioutil.ReadFile(script)
+	if err != nil {
+		return false, err
+	}
+	return scriptOutputPattern.Match(b), nil
+}
+
+// compareOutputs compares files written in the directory with files in the working tree and prints the difference.
+func compareOutputs(outDir string) ([]string, error) {
+	stale := []string{}
+	err := filepath.Walk(outDir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(outDir, p)
+		if err != nil {
+			return err
+		}
+		filename := filepath.Join(string(filepath.Separator), rel)
+
+		generated, err := ioutil.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		committed, err := ioutil.ReadFile(filename)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if bytes.Equal(generated, committed) {
+			return nil
+		}
+
+		name := filename
+		if wd, err := os.Getwd(); err == nil {
+			if r, err := filepath.Rel(wd, filename); err == nil && !strings.HasPrefix(r, "..") {
+				name = r
+			}
+		}
+		stale = append(stale, name)
+
+		return writeUnifiedDiff(os.Stdout, "a/"+filepath.ToSlash(name), "b/"+filepath.ToSlash(name), string(committed), string(generated), 3)
+	})
+	return stale, err
+}
+
+func newVerify(c *config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "verify [packages...]",
+		Short: "Verify generated files are up to date",
+		Long: `Verify generated files are up to date.
+
+Scripts created by "gogtok new" found in //go:generate directives are run with outputs of "gogtok write" and -o of gogtok commands redirected into a temporary directory.
+The outputs are compared with files in the working tree, and differences are printed in the unified format.
+The working tree is not modified. Scripts which do not write outputs with gogtok are reported as errors.`,
+		Example: `  gogtok verify ./...`,
+		RunE: func(_ *cobra.Command, args []string) error {
+			dirs, err := query.ExpandPatterns(args)
+			if err != nil {
+				return err
+			}
+
+			directives, err := query.GenerateDirectives(c.queryConfig(false), dirs...)
+			if err != nil {
+				return err
+			}
+
+			tmpDir, err := ioutil.TempDir("", "gogtok-verify")
+			if err != nil {
+				return err
+			}
+			defer os.RemoveAll(tmpDir)
+
+			stale := []string{}
+			for i, gd := range directives {
+				if !gd.Gogtok {
+					continue
+				}
+
+				ok, err := writesOutputs(gd.Script)
+				if err != nil {
+					return err
+				}
+				if !ok {
+					return fmt.Errorf("%s:%d: %s does not write outputs with gogtok", gd.File, gd.Line, gd.Script)
+				}
+
+				outDir := filepath.Join(tmpDir, fmt.Sprint(i))
+				cmd, err := generateCommand(gd, writeDirEnv+"="+outDir)
+				if err != nil {
+					return err
+				}
+				cmd.Stdout = os.Stderr
+				cmd.Stderr = os.Stderr
+				if err := cmd.Run(); err != nil {
+					return fmt.Errorf("%s:%d: %s: %v", gd.File, gd.Line, gd.Command, err)
+				}
+
+				if _, err := os.Stat(outDir); os.IsNotExist(err) {
+					continue
+				}
+				files, err := compareOutputs(outDir)
+				if err != nil {
+					return err
+				}
+				stale = append(stale, files...)
+			}
+
+			if len(stale) > 0 {
+				return fmt.Errorf("%d generated files are stale: %s", len(stale), strings.Join(stale, ", "))
+			}
+			return nil
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,101 @@
+package command
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestVerify(t *testing.T) {
+	dir := newTestModule(t, map[string]string{
+		"a.go": "package test\n\n//go:generate ./gen.sh\n",
+		// gogtok is replaced by a function writing stdin into the file like "gogtok write"
+		"gen.sh": `#!/bin/sh
+gogtok() {
+	out="$2"
+	if [ -n "$GOGTOK_WRITE_DIR" ]; then
+		out="$GOGTOK_WRITE_DIR$(pwd)/$2"
+		mkdir -p "$(dirname "$out")"
+	fi
+	cat > "$out"
+}
+printf "// Code generated by './gen.sh'. DO NOT EDIT.\npackage test\n" | gogtok write gen.go
+`,
+	})
+	if err := os.Chmod(filepath.Join(dir, "gen.sh"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := runGogtok("run", dir); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runGogtok("verify", dir); err != nil {
+		t.Errorf("generated files must be up to date: %v", err)
+	}
+
+	// Stale files are reported without modifying the working tree
+	genFile := filepath.Join(dir, "gen.go")
+	if err := ioutil.WriteFile(genFile, []byte("package test\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err := runGogtok("verify", dir)
+	if err == nil || !strings.Contains(err.Error(), "1 generated files are stale") {
+		t.Errorf("stale files must be reported: %v", err)
+	}
+	if b, err := ioutil.ReadFile(genFile); err != nil || string(b) != "package test\n" {
+		t.Errorf("the working tree must not be modified: %q, %v", b, err)
+	}
+
+	// Scripts without outputs cannot be verified
+	err = ioutil.WriteFile(filepath.Join(dir, "gen.sh"), []byte("#!/bin/sh\necho \"// Code generated by './gen.sh'. DO NOT EDIT.\"\n"), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = runGogtok("verify", dir)
+	if err == nil || !strings.Contains(err.Error(), "does not write outputs with gogtok") {
+		t.Errorf("scripts without outputs must be reported: %v", err)
+	}
+}
+
+func TestCompareOutputs(t *testing.T) {
+	dir := t.TempDir()
+	outDir := t.TempDir()
+
+	files := map[string]string{
+		"same.go":    "package a\n",
+		"changed.go": "package a\n\nvar x = 1\n",
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	generated := map[string]string{
+		"same.go":    "package a\n",
+		"changed.go": "package a\n\nvar x = 2\n",
+		"new.go":     "package a\n",
+	}
+	for name, content := range generated {
+		filename := filepath.Join(outDir, dir, name)
+		if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filename, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stale, err := compareOutputs(outDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := []string{}
+	for _, name := range stale {
+		got = append(got, filepath.Base(name))
+	}
+	if strings.Join(got, " ") != "changed.go new.go" {
+		t.Errorf("compareOutputs() = %v", stale)
+	}
+}
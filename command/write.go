@@ -0,0 +1,142 @@
+package command
+
+import (
+	"bytes"
+	"go/parser"
+	"go/token"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// writeDirEnv is the environment variable to redirect outputs of "gogtok write" into the directory.
+// Files are written in the directory with their absolute paths.
+const writeDirEnv = "GOGTOK_WRITE_DIR"
+
+// outputPath returns the path where "gogtok write" writes the file.
+func outputPath(filename string) (string, error) {
+	dir := os.Getenv(writeDirEnv)
+	if dir == "" {
+		return filename, nil
+	}
+
+	abs, err := filepath.Abs(filename)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, abs), nil
+}
+
+// writeFileIfChanged replaces the file with a temporary file only if the content is changed.
+// The file is kept if it fails to write.
+func writeFileIfChanged(filename string, b []byte) (bool, error) {
+	perm := os.FileMode(0644)
+	if info, err := os.Stat(filename); err == nil {
+		perm = info.Mode().Perm()
+
+		old, err := ioutil.ReadFile(filename)
+		if err != nil {
+			return false, err
+		}
+		if bytes.Equal(old, b) {
+			return false, nil
+		}
+	} else if !os.IsNotExist(err) {
+		return false, err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(filename), "."+filepath.Base(filename)+".")
+	if err != nil {
+		return false, err
+	}
+	tmpName := tmp.Name()
+
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		os.Remove(tmpName)
+		return false, err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpName)
+		return false, err
+	}
+	if err := os.Chmod(tmpName, perm); err != nil {
+		os.Remove(tmpName)
+		return false, err
+	}
+	if err := os.Rename(tmpName, filename); err != nil {
+		os.Remove(tmpName)
+		return false, err
+	}
+	return true, nil
+}
+
+// writeOutput writes the generated file if it is changed.
+// The file is redirected if GOGTOK_WRITE_DIR is set.
+func writeOutput(filename string, b []byte) error {
+	output, err := outputPath(filename)
+	if err != nil {
+		return err
+	}
+	if output != filename {
+		if err := os.MkdirAll(filepath.Dir(output), 0755); err != nil {
+			return err
+		}
+	}
+
+	changed, err := writeFileIfChanged(output, b)
+	if err != nil {
+		return err
+	}
+	if !changed {
+		logrus.Debugf("%s is not changed", filename)
+	}
+	return nil
+}
+
+func newWrite() *cobra.Command {
+	validate := true
+
+	cmd := &cobra.Command{
+		Use:   "write FILE",
+		Short: "Write Go source from stdin into the file if it is changed",
+		Long: `Write Go source from stdin into the file if it is changed.
+
+The source is validated before writing and the file is replaced atomically.
+The file is kept if the source is invalid, so that a failed pipeline does not leave a half-written file.
+The file is not touched if the content is not changed.
+If ` + writeDirEnv + ` is set, the file is written in the directory with its absolute path instead.`,
+		Example: `  render_file | gogtok fmt | gogtok write file.go`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			filename := args[0]
+
+			b, err := ioutil.ReadAll(os.Stdin)
+			if err != nil {
+				return err
+			}
+
+			if validate {
+				fset := token.NewFileSet()
+				if _, err := parser.ParseFile(fset, filename, b, parser.AllErrors); err != nil {
+					err = sourceError(b, err)
+					if err, ok := err.(*syntaxError); ok {
+						io.WriteString(os.Stderr, err.context)
+					}
+					return err
+				}
+			}
+
+			return writeOutput(filename, b)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.BoolVar(&validate, "validate", validate, "Validate the source as Go")
+
+	return cmd
+}
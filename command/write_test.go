@@ -0,0 +1,104 @@
+package command
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeStdin runs "gogtok write" with the source as stdin.
+func writeStdin(t *testing.T, src string, args ...string) error {
+	t.Helper()
+
+	in := filepath.Join(t.TempDir(), "stdin")
+	if err := ioutil.WriteFile(in, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	stdin := os.Stdin
+	os.Stdin = f
+	defer func() {
+		os.Stdin = stdin
+	}()
+	return runGogtok(append([]string{"write"}, args...)...)
+}
+
+func TestWriteFileIfChanged(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "a.go")
+
+	if changed, err := writeFileIfChanged(filename, []byte("a")); !changed || err != nil {
+		t.Errorf("writeFileIfChanged() of a new file = %v, %v", changed, err)
+	}
+	if err := os.Chmod(filename, 0600); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-time.Hour).Truncate(time.Second)
+	if err := os.Chtimes(filename, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	if changed, err := writeFileIfChanged(filename, []byte("a")); changed || err != nil {
+		t.Errorf("writeFileIfChanged() of the same content = %v, %v", changed, err)
+	}
+	if info, err := os.Stat(filename); err != nil || !info.ModTime().Equal(old) {
+		t.Errorf("the file must not be touched: %v", err)
+	}
+
+	if changed, err := writeFileIfChanged(filename, []byte("b")); !changed || err != nil {
+		t.Errorf("writeFileIfChanged() of a new content = %v, %v", changed, err)
+	}
+	info, err := os.Stat(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("permission is changed to %v", info.Mode().Perm())
+	}
+	if b, err := ioutil.ReadFile(filename); err != nil || string(b) != "b" {
+		t.Errorf("content = %q, %v", b, err)
+	}
+
+	if matches, _ := filepath.Glob(filepath.Join(filepath.Dir(filename), ".*")); len(matches) > 0 {
+		t.Errorf("temporary files are left: %v", matches)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "a.go")
+	t.Setenv(writeDirEnv, "")
+
+	if err := writeStdin(t, "package a\n", filename); err != nil {
+		t.Fatal(err)
+	}
+
+	// Invalid sources keep the file
+	if err := writeStdin(t, "package a\nfunc {\n", filename); err == nil {
+		t.Error("invalid sources must be rejected")
+	}
+	if err := writeStdin(t, "not go", "--validate=false", filepath.Join(filepath.Dir(filename), "a.txt")); err != nil {
+		t.Error(err)
+	}
+	if b, err := ioutil.ReadFile(filename); err != nil || string(b) != "package a\n" {
+		t.Errorf("content = %q, %v", b, err)
+	}
+
+	// Files are redirected into the directory with their absolute paths
+	writeDir := t.TempDir()
+	t.Setenv(writeDirEnv, writeDir)
+	if err := writeStdin(t, "package b\n", filename); err != nil {
+		t.Fatal(err)
+	}
+	if b, err := ioutil.ReadFile(filepath.Join(writeDir, filename)); err != nil || string(b) != "package b\n" {
+		t.Errorf("redirected content = %q, %v", b, err)
+	}
+	if b, err := ioutil.ReadFile(filename); err != nil || string(b) != "package a\n" {
+		t.Errorf("the file must not be written with %s: %q, %v", writeDirEnv, b, err)
+	}
+}
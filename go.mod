@@ -1,9 +1,16 @@
 module github.com/utisam/gogtok
 
-go 1.12
+go 1.18
 
 require (
 	github.com/sirkon/goproxy v1.4.0
 	github.com/sirupsen/logrus v1.4.2
 	github.com/spf13/cobra v0.0.5
+	github.com/spf13/pflag v1.0.3
+	gopkg.in/yaml.v2 v2.4.0
+)
+
+require (
+	github.com/pkg/errors v0.8.1 // indirect
+	golang.org/x/sys v0.0.0-20190422165155-953cdadca894 // indirect
 )
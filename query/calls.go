@@ -0,0 +1,79 @@
+package query
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+)
+
+// Call is a call expression of a function.
+type Call struct {
+	Pos token.Position
+	// Package is the path of the package calling the function.
+	Package string
+	// Func is the name of the function declaration calling the function such as "Func" or "Type.Method".
+	Func string
+	// Args are sources of the arguments.
+	Args []string
+	// Consts are values of the arguments if they are constant. See ConstantString.
+	Consts []string
+}
+
+// Calls returns calls of the functions in the packages.
+func Calls(pkgs []*Package, objs []types.Object) []*Call {
+	res := []*Call{}
+	for _, pkg := range pkgs {
+		keys := objectKeySet(pkg.Fset, objs)
+		for _, f := range pkg.Files {
+			inspectWithStack(f, func(n ast.Node, stack []ast.Node) {
+				call, ok := n.(*ast.CallExpr)
+				if !ok {
+					return
+				}
+				ident := calleeIdent(call)
+				if ident == nil {
+					return
+				}
+				obj := pkg.Info.Uses[ident]
+				if obj == nil || !keys[ObjectKey(pkg.Fset, obj)] {
+					return
+				}
+
+				c := &Call{
+					Pos:     pkg.Fset.Position(call.Pos()),
+					Package: pkg.Types.Path(),
+					Func:    enclosingFuncName(stack),
+					Args:    make([]string, len(call.Args)),
+					Consts:  make([]string, len(call.Args)),
+				}
+				for i, arg := range call.Args {
+					c.Args[i] = NodeString(pkg.Fset, arg)
+					c.Consts[i] = ConstantString(pkg.Info, arg)
+				}
+				res = append(res, c)
+			})
+		}
+	}
+	return res
+}
+
+// calleeIdent returns the identifier of the called function.
+func calleeIdent(call *ast.CallExpr) *ast.Ident {
+	fun := call.Fun
+	for {
+		switch f := fun.(type) {
+		case *ast.ParenExpr:
+			fun = f.X
+		case *ast.IndexExpr:
+			fun = f.X
+		case *ast.IndexListExpr:
+			fun = f.X
+		case *ast.Ident:
+			return f
+		case *ast.SelectorExpr:
+			return f.Sel
+		default:
+			return nil
+		}
+	}
+}
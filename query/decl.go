@@ -0,0 +1,158 @@
+package query
+
+import (
+	"go/ast"
+	"go/token"
+	"reflect"
+	"strings"
+)
+
+// Func is a function or a method declared in the file.
+type Func struct {
+	Name string
+	// Recv is the type name of the receiver or empty if it is not a method.
+	Recv string
+}
+
+// Funcs returns functions and methods declared in the file.
+func Funcs(f *ast.File) []*Func {
+	res := []*Func{}
+	for _, decl := range f.Decls {
+		fnDecl, ok := decl.(*ast.FuncDecl)
+		if !ok {
+			continue
+		}
+
+		fn := &Func{Name: fnDecl.Name.Name}
+		if fnDecl.Recv != nil && len(fnDecl.Recv.List) > 0 {
+			fn.Recv = ReceiverTypeName(fnDecl.Recv.List[0].Type)
+		}
+		res = append(res, fn)
+	}
+	return res
+}
+
+// Value is a variable or a constant declared in the file.
+type Value struct {
+	Name string
+	// Type is the declared type.
+	// Specs without types inherit the type of the previous spec in the same group like iota constants.
+	Type  string
+	Const bool
+}
+
+// Values returns variables and constants declared in the file.
+func Values(f *ast.File) []*Value {
+	res := []*Value{}
+	for _, decl := range f.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || (genDecl.Tok != token.VAR && genDecl.Tok != token.CONST) {
+			continue
+		}
+
+		declType := ""
+		for _, spec := range genDecl.Specs {
+			valSpec, ok := spec.(*ast.ValueSpec)
+			if !ok {
+				continue
+			}
+
+			if valSpec.Type != nil {
+				declType = TypeString(valSpec.Type)
+			}
+
+			for _, nameIdent := range valSpec.Names {
+				res = append(res, &Value{
+					Name:  nameIdent.Name,
+					Type:  declType,
+					Const: genDecl.Tok == token.CONST,
+				})
+			}
+		}
+	}
+	return res
+}
+
+// TypeDecl is a type declared in the file.
+type TypeDecl struct {
+	Name string
+}
+
+// Types returns types declared in the file.
+func Types(f *ast.File) []*TypeDecl {
+	res := []*TypeDecl{}
+	forEachTypeSpec(f, func(typeSpec *ast.TypeSpec) {
+		res = append(res, &TypeDecl{
+			Name: typeSpec.Name.Name,
+		})
+	})
+	return res
+}
+
+func forEachTypeSpec(f *ast.File, fn func(*ast.TypeSpec)) {
+	for _, decl := range f.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.TYPE {
+			continue
+		}
+
+		for _, spec := range genDecl.Specs {
+			if typeSpec, ok := spec.(*ast.TypeSpec); ok {
+				fn(typeSpec)
+			}
+		}
+	}
+}
+
+// Field is a field of a struct or a method of an interface.
+type Field struct {
+	Name string
+	Type string
+	// Tag is the struct tag without quotes.
+	Tag string
+}
+
+// TagValue returns the value of the key in the tag.
+func (f *Field) TagValue(key string) string {
+	return reflect.StructTag(f.Tag).Get(key)
+}
+
+func newField(name string, typeExpr ast.Expr, tagLit *ast.BasicLit) *Field {
+	tag := ""
+	if tagLit != nil {
+		tag = strings.Trim(tagLit.Value, "`")
+	}
+
+	return &Field{
+		Name: name,
+		Type: TypeString(typeExpr),
+		Tag:  tag,
+	}
+}
+
+// Fields returns named fields of the struct or methods of the interface declared in the file.
+func Fields(f *ast.File, typeName string) []*Field {
+	res := []*Field{}
+	forEachTypeSpec(f, func(typeSpec *ast.TypeSpec) {
+		if typeSpec.Name.Name != typeName {
+			return
+		}
+
+		var fields *ast.FieldList
+		switch specType := typeSpec.Type.(type) {
+		case *ast.StructType:
+			fields = specType.Fields
+		case *ast.InterfaceType:
+			fields = specType.Methods
+		default:
+			return
+		}
+
+		for _, field := range fields.List {
+			for _, nameIdent := range field.Names {
+				res = append(res, newField(nameIdent.Name, field.Type, field.Tag))
+			}
+		}
+	})
+	return res
+}
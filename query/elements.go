@@ -0,0 +1,151 @@
+package query
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/printer"
+	"go/token"
+	"go/types"
+	"strconv"
+)
+
+// Element is an element of a composite literal.
+type Element struct {
+	Pos token.Position
+	// Path is like "[0]", "[\"key\"]" or "[\"key\"].Field" for nested elements.
+	Path  string
+	Index int
+	// Key is the source of the key or empty if the element is not keyed.
+	Key string
+	// KeyConst is the value of the key if it is constant or the name of the field.
+	KeyConst string
+	// Value is the source of the value.
+	Value string
+	// Const is the value if it is constant. See ConstantString.
+	Const string
+}
+
+// NodeString renders the node.
+func NodeString(fset *token.FileSet, node ast.Node) string {
+	b := &bytes.Buffer{}
+	if err := printer.Fprint(b, fset, node); err != nil {
+		return ""
+	}
+	return b.String()
+}
+
+// ConstantString formats the value of the constant expression.
+// Strings are unquoted. Empty string is returned if the expression is not constant.
+func ConstantString(info *types.Info, expr ast.Expr) string {
+	tv, ok := info.Types[expr]
+	if !ok || tv.Value == nil {
+		return ""
+	}
+
+	switch tv.Value.Kind() {
+	case constant.String:
+		return constant.StringVal(tv.Value)
+	case constant.Float:
+		f, _ := constant.Float64Val(tv.Value)
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	}
+	return tv.Value.ExactString()
+}
+
+func compositeLitOf(expr ast.Expr) *ast.CompositeLit {
+	switch e := expr.(type) {
+	case *ast.CompositeLit:
+		return e
+	case *ast.UnaryExpr:
+		if e.Op == token.AND {
+			return compositeLitOf(e.X)
+		}
+	case *ast.ParenExpr:
+		return compositeLitOf(e.X)
+	}
+	return nil
+}
+
+// forEachElement calls fn with elements of the composite literal.
+// Elements of nested composite literals are visited after their parents if nested is true.
+func forEachElement(fset *token.FileSet, info *types.Info, lit *ast.CompositeLit, parentPath string, nested bool, fn func(*Element)) {
+	litType := info.TypeOf(lit)
+	// Types of elided literals in "[]*T{{...}}" are pointers
+	if ptr, ok := litType.Underlying().(*types.Pointer); ok {
+		litType = ptr.Elem()
+	}
+	_, isStruct := litType.Underlying().(*types.Struct)
+
+	for i, elt := range lit.Elts {
+		e := &Element{
+			Pos:   fset.Position(elt.Pos()),
+			Index: i,
+		}
+
+		valueExpr := elt
+		if kv, ok := elt.(*ast.KeyValueExpr); ok {
+			valueExpr = kv.Value
+			e.Key = NodeString(fset, kv.Key)
+			e.KeyConst = ConstantString(info, kv.Key)
+			if ident, ok := kv.Key.(*ast.Ident); ok && isStruct {
+				e.KeyConst = ident.Name
+			}
+		}
+		e.Value = NodeString(fset, valueExpr)
+		e.Const = ConstantString(info, valueExpr)
+
+		switch {
+		case isStruct && e.Key != "":
+			e.Path = parentPath + "." + e.Key
+		case e.Key != "":
+			e.Path = parentPath + "[" + e.Key + "]"
+		default:
+			e.Path = parentPath + "[" + strconv.Itoa(i) + "]"
+		}
+
+		fn(e)
+
+		if child := compositeLitOf(valueExpr); nested && child != nil && info.TypeOf(child) != nil {
+			forEachElement(fset, info, child, e.Path, nested, fn)
+		}
+	}
+}
+
+// findVarValue finds the initializer of the variable in the file.
+func findVarValue(f *ast.File, name string) ast.Expr {
+	for _, decl := range f.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.VAR {
+			continue
+		}
+
+		for _, spec := range genDecl.Specs {
+			valSpec, ok := spec.(*ast.ValueSpec)
+			if !ok {
+				continue
+			}
+			for i, nameIdent := range valSpec.Names {
+				if nameIdent.Name == name && i < len(valSpec.Values) {
+					return valSpec.Values[i]
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Elements returns elements of the composite literal initializing the variable declared in the file of the package.
+func Elements(pkg *Package, f *ast.File, varName string, nested bool) ([]*Element, error) {
+	lit := compositeLitOf(findVarValue(f, varName))
+	if lit == nil || pkg.Info.TypeOf(lit) == nil {
+		return nil, fmt.Errorf("composite literal not found: %s", varName)
+	}
+
+	res := []*Element{}
+	forEachElement(pkg.Fset, pkg.Info, lit, "", nested, func(e *Element) {
+		res = append(res, e)
+	})
+	return res, nil
+}
@@ -0,0 +1,84 @@
+package query
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestElements(t *testing.T) {
+	dir := newTestDir(t, map[string]string{
+		"a.go": `package a
+
+const prefix = "p_"
+
+type Route struct {
+	Path    string
+	Methods []string
+	Weight  float64
+}
+
+var routes = map[string]*Route{
+	prefix + "index": {Path: "/", Methods: []string{"GET"}},
+	"api":            &Route{Path: "/api", Weight: 1.0 / 4},
+}
+
+var list = []int{1 << 3, len(prefix), 5: -1}
+
+var notLiteral = len(prefix)
+`,
+	})
+
+	pkgs, err := Load(nil, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg := pkgs[0]
+	f := pkg.File(filepath.Join(dir, "a.go"))
+
+	tests := []struct {
+		name   string
+		nested bool
+		want   []string
+	}{
+		{"list", false, []string{
+			"[0] 0 |   | 1 << 3 | 8",
+			"[1] 1 |   | len(prefix) | 2",
+			"[5] 2 | 5 5 | -1 | -1",
+		}},
+		{"routes", false, []string{
+			`[prefix + "index"] 0 | prefix + "index" p_index | {Path: "/", Methods: []string{"GET"}} | `,
+			`["api"] 1 | "api" api | &Route{Path: "/api", Weight: 1.0 / 4} | `,
+		}},
+		{"routes", true, []string{
+			`[prefix + "index"] 0 | prefix + "index" p_index | {Path: "/", Methods: []string{"GET"}} | `,
+			`[prefix + "index"].Path 0 | Path Path | "/" | /`,
+			`[prefix + "index"].Methods 1 | Methods Methods | []string{"GET"} | `,
+			`[prefix + "index"].Methods[0] 0 |   | "GET" | GET`,
+			`["api"] 1 | "api" api | &Route{Path: "/api", Weight: 1.0 / 4} | `,
+			`["api"].Path 0 | Path Path | "/api" | /api`,
+			`["api"].Weight 1 | Weight Weight | 1.0 / 4 | 0.25`,
+		}},
+	}
+	for _, tt := range tests {
+		elements, err := Elements(pkg, f, tt.name, tt.nested)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := []string{}
+		for _, e := range elements {
+			got = append(got, strings.Join([]string{e.Path + " " + fmt.Sprint(e.Index), e.Key + " " + e.KeyConst, e.Value, e.Const}, " | "))
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Elements(%s, %v) =\n%s\nwant\n%s", tt.name, tt.nested, strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+		}
+	}
+
+	for _, name := range []string{"notLiteral", "missing"} {
+		if _, err := Elements(pkg, f, name, false); err == nil {
+			t.Errorf("Elements(%s) must fail", name)
+		}
+	}
+}
@@ -0,0 +1,242 @@
+package query
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Embed is a pattern of a "//go:embed" directive.
+type Embed struct {
+	Pos token.Position
+	// Var is the name of the annotated variable.
+	Var string
+	// Type is one of "string", "[]byte", "embed.FS" or the declared type if it is invalid.
+	Type    string
+	Pattern string
+	// Files are paths matched with the pattern relative to the directory of the package.
+	Files []string
+	// Status is one of "ok", "invalid", "nomatch" and "multiple".
+	Status string
+}
+
+// Embeds returns patterns of "//go:embed" directives in the file of the package in dir.
+func Embeds(dir string, fset *token.FileSet, f *ast.File) ([]*Embed, error) {
+	res := []*Embed{}
+
+	var inspectErr error
+	forEachEmbedDirective(f, func(comment *ast.Comment, spec *ast.ValueSpec) {
+		if inspectErr != nil {
+			return
+		}
+
+		patterns, err := parseEmbedPatterns(comment.Text[len("//go:embed "):])
+		if err != nil {
+			inspectErr = err
+			return
+		}
+
+		varType := embedVarType(f, spec.Type)
+		for _, pattern := range patterns {
+			e := &Embed{
+				Pos:     fset.Position(comment.Pos()),
+				Var:     spec.Names[0].Name,
+				Type:    varType,
+				Pattern: pattern,
+				Status:  "ok",
+			}
+
+			files, err := matchEmbedPattern(dir, pattern)
+			switch {
+			case err != nil:
+				e.Status = "invalid"
+			case len(files) == 0:
+				e.Status = "nomatch"
+			case varType != "embed.FS" && (len(patterns) > 1 || len(files) > 1):
+				e.Status = "multiple"
+			}
+			e.Files = files
+
+			res = append(res, e)
+		}
+	})
+	if inspectErr != nil {
+		return nil, inspectErr
+	}
+	return res, nil
+}
+
+// parseEmbedPatterns splits patterns of "//go:embed" which may be quoted.
+func parseEmbedPatterns(s string) ([]string, error) {
+	patterns := []string{}
+	for {
+		s = strings.TrimLeft(s, " \t")
+		if s == "" {
+			return patterns, nil
+		}
+
+		var n int
+		switch s[0] {
+		case '"':
+			n = quotedStringLen(s)
+		case '`':
+			n = strings.IndexByte(s[1:], '`') + 2
+			if n == 1 {
+				n = -1
+			}
+		default:
+			n = strings.IndexAny(s, " \t")
+			if n < 0 {
+				n = len(s)
+			}
+		}
+		if n < 0 {
+			return nil, fmt.Errorf("invalid quoted string in //go:embed: %s", s)
+		}
+
+		pattern := s[:n]
+		if pattern[0] == '"' || pattern[0] == '`' {
+			unquoted, err := strconv.Unquote(pattern)
+			if err != nil {
+				return nil, err
+			}
+			pattern = unquoted
+		}
+		patterns = append(patterns, pattern)
+		s = s[n:]
+	}
+}
+
+// embedVarType classifies the type of the variable for "//go:embed".
+func embedVarType(f *ast.File, typeExpr ast.Expr) string {
+	if typeExpr == nil {
+		return ""
+	}
+	t := TypeString(typeExpr)
+	switch t {
+	case "string", "[]byte":
+		return t
+	}
+
+	embedName := ""
+	for _, spec := range f.Imports {
+		if p, err := strconv.Unquote(spec.Path.Value); err == nil && p == "embed" {
+			embedName = "embed"
+			if spec.Name != nil {
+				embedName = spec.Name.Name
+			}
+		}
+	}
+	if embedName != "" && (t == embedName+".FS" || (embedName == "." && t == "FS")) {
+		return "embed.FS"
+	}
+	return t
+}
+
+// matchEmbedPattern returns files matched with the pattern like "go build".
+func matchEmbedPattern(dir, pattern string) ([]string, error) {
+	all := strings.HasPrefix(pattern, "all:")
+	glob := strings.TrimPrefix(pattern, "all:")
+
+	if _, err := path.Match(glob, ""); err != nil || glob == "" || strings.HasPrefix(glob, "/") {
+		return nil, fmt.Errorf("invalid pattern syntax")
+	}
+	for _, elem := range strings.Split(glob, "/") {
+		if elem == "" || elem == "." || elem == ".." {
+			return nil, fmt.Errorf("invalid pattern syntax")
+		}
+	}
+
+	matches, err := filepath.Glob(filepath.Join(dir, filepath.FromSlash(glob)))
+	if err != nil {
+		return nil, err
+	}
+
+	files := []string{}
+	for _, match := range matches {
+		info, err := os.Stat(match)
+		if err != nil {
+			return nil, err
+		}
+
+		if !info.IsDir() {
+			rel, err := filepath.Rel(dir, match)
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, filepath.ToSlash(rel))
+			continue
+		}
+
+		err = filepath.Walk(match, func(p string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if p != match {
+				name := info.Name()
+				if !all && (strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+					if info.IsDir() {
+						return filepath.SkipDir
+					}
+					return nil
+				}
+				if info.IsDir() {
+					if _, err := os.Stat(filepath.Join(p, "go.mod")); err == nil {
+						return filepath.SkipDir
+					}
+				}
+			}
+			if info.Mode().IsRegular() {
+				rel, err := filepath.Rel(dir, p)
+				if err != nil {
+					return err
+				}
+				files = append(files, filepath.ToSlash(rel))
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
+
+// forEachEmbedDirective calls fn with "//go:embed" lines annotating the variable.
+func forEachEmbedDirective(f *ast.File, fn func(comment *ast.Comment, spec *ast.ValueSpec)) {
+	for _, decl := range f.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.VAR {
+			continue
+		}
+
+		for _, spec := range genDecl.Specs {
+			valSpec, ok := spec.(*ast.ValueSpec)
+			if !ok {
+				continue
+			}
+
+			doc := valSpec.Doc
+			if doc == nil && !genDecl.Lparen.IsValid() {
+				doc = genDecl.Doc
+			}
+			if doc == nil {
+				continue
+			}
+
+			for _, comment := range doc.List {
+				if strings.HasPrefix(comment.Text, "//go:embed ") || strings.HasPrefix(comment.Text, "//go:embed\t") {
+					fn(comment, valSpec)
+				}
+			}
+		}
+	}
+}
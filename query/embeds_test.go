@@ -0,0 +1,102 @@
+package query
+
+import (
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseEmbedPatterns(t *testing.T) {
+	tests := []struct {
+		src  string
+		want []string
+	}{
+		{"a.txt", []string{"a.txt"}},
+		{"  a.txt\tstatic/*  ", []string{"a.txt", "static/*"}},
+		{"\"with space.txt\" `raw name.txt` b", []string{"with space.txt", "raw name.txt", "b"}},
+	}
+	for _, tt := range tests {
+		got, err := parseEmbedPatterns(tt.src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseEmbedPatterns(%q) = %q, want %q", tt.src, got, tt.want)
+		}
+	}
+
+	for _, src := range []string{`"a.txt`, "`a.txt"} {
+		if _, err := parseEmbedPatterns(src); err == nil {
+			t.Errorf("parseEmbedPatterns(%q) must fail", src)
+		}
+	}
+}
+
+func TestEmbeds(t *testing.T) {
+	dir := newTestDir(t, map[string]string{
+		"a.go": `package a
+
+import (
+	"embed"
+	e "embed"
+)
+
+//go:embed a.txt
+var text string
+
+//go:embed static
+var static embed.FS
+
+//go:embed all:static
+var all e.FS
+
+//go:embed a.txt static/*.css
+var multiple []byte
+
+var (
+	//go:embed missing.txt ../a.txt
+	missing string
+
+	//go:embed a.txt
+	other int
+)
+`,
+		"a.txt":              "a",
+		"static/a.css":       "a",
+		"static/.hidden":     "hidden",
+		"static/_tmp/b.css":  "b",
+		"static/sub/go.mod":  "module sub",
+		"static/sub/c.css":   "c",
+		"static/nested/d.js": "d",
+	})
+
+	fset := token.NewFileSet()
+	f, err := ParseFile(fset, filepath.Join(dir, "a.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	embeds, err := Embeds(dir, fset, f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := []string{}
+	for _, e := range embeds {
+		got = append(got, strings.Join([]string{e.Var, e.Type, e.Pattern, strings.Join(e.Files, ","), e.Status}, " "))
+	}
+	want := []string{
+		"text string a.txt a.txt ok",
+		"static embed.FS static static/a.css,static/nested/d.js ok",
+		"all embed.FS all:static static/.hidden,static/_tmp/b.css,static/a.css,static/nested/d.js ok",
+		"multiple []byte a.txt a.txt multiple",
+		"multiple []byte static/*.css static/a.css multiple",
+		"missing string missing.txt  nomatch",
+		"missing string ../a.txt  invalid",
+		"other int a.txt a.txt ok",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Embeds() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
@@ -0,0 +1,201 @@
+package query
+
+import (
+	"bufio"
+	"errors"
+	"go/build"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ScriptHeaderPattern matches the header rendered by scripts created by "gogtok new".
+var ScriptHeaderPattern = regexp.MustCompile(`// Code generated by '\./[^']+\.sh'\. DO NOT EDIT\.`)
+
+// GenerateDirective is a "//go:generate" directive.
+type GenerateDirective struct {
+	// Dir is the directory of the package where "go generate" runs the command.
+	Dir     string
+	File    string
+	Line    int
+	Package string
+	// Command is the text of the directive.
+	Command string
+	// Args are words of the command with expanded variables.
+	Args []string
+	// Script is the path of the script invoked by the command if it is a file.
+	Script string
+	// Gogtok reports whether the script is created by "gogtok new".
+	Gogtok bool
+}
+
+// Getenv returns variables which "go generate" defines for the directive.
+func (gd *GenerateDirective) Getenv(key string) string {
+	switch key {
+	case "GOFILE":
+		return filepath.Base(gd.File)
+	case "GOLINE":
+		return strconv.Itoa(gd.Line)
+	case "GOPACKAGE":
+		return gd.Package
+	case "GOARCH":
+		return build.Default.GOARCH
+	case "GOOS":
+		return build.Default.GOOS
+	case "DOLLAR":
+		return "$"
+	}
+	return os.Getenv(key)
+}
+
+// Environ returns environment variables to run the directive.
+func (gd *GenerateDirective) Environ() []string {
+	env := os.Environ()
+	for _, key := range []string{"GOFILE", "GOLINE", "GOPACKAGE", "GOARCH", "GOOS", "DOLLAR"} {
+		env = append(env, key+"="+gd.Getenv(key))
+	}
+	return env
+}
+
+// splitGenerateArgs splits the directive into words like "go generate".
+func splitGenerateArgs(line string) ([]string, error) {
+	words := []string{}
+	for {
+		line = strings.TrimLeft(line, " \t")
+		if line == "" {
+			return words, nil
+		}
+
+		n := strings.IndexAny(line, " \t")
+		if n < 0 {
+			n = len(line)
+		}
+		if line[0] == '"' {
+			n = quotedStringLen(line)
+			if n < 0 {
+				return nil, errors.New("unterminated quoted string")
+			}
+		}
+
+		word := line[:n]
+		if word[0] == '"' {
+			s, err := strconv.Unquote(word)
+			if err != nil {
+				return nil, err
+			}
+			word = s
+		}
+		words = append(words, word)
+		line = line[n:]
+	}
+}
+
+func quotedStringLen(s string) int {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+	return -1
+}
+
+// IsGogtokScript reports whether the file is created by "gogtok new".
+func IsGogtokScript(filename string) bool {
+	info, err := os.Stat(filename)
+	if err != nil || info.IsDir() {
+		return false
+	}
+
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return false
+	}
+	return ScriptHeaderPattern.Match(b)
+}
+
+// resolveGenerateScript finds a script file invoked by the directive.
+func resolveGenerateScript(dir string, args []string) string {
+	if len(args) > 1 && (args[0] == "sh" || args[0] == "bash" || args[0] == "/bin/sh" || args[0] == "/bin/bash") {
+		args = args[1:]
+	}
+	if len(args) == 0 || !strings.ContainsRune(args[0], '/') {
+		return ""
+	}
+
+	script := args[0]
+	if !filepath.IsAbs(script) {
+		script = filepath.Join(dir, script)
+	}
+	return script
+}
+
+// GenerateDirectives scans "//go:generate" directives in files of the directories selected by the config.
+func GenerateDirectives(cfg *LoadConfig, dirs ...string) ([]*GenerateDirective, error) {
+	res := []*GenerateDirective{}
+	for _, dir := range dirs {
+		buildPkg, err := cfg.BuildContext().ImportDir(dir, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		filenames := []string{}
+		for _, files := range [][]string{buildPkg.GoFiles, buildPkg.CgoFiles, buildPkg.TestGoFiles, buildPkg.XTestGoFiles} {
+			filenames = append(filenames, files...)
+		}
+
+		for _, filename := range filenames {
+			directives, err := scanGenerateDirectives(dir, filepath.Join(dir, filename), buildPkg.Name)
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, directives...)
+		}
+	}
+	return res, nil
+}
+
+func scanGenerateDirectives(dir, filename, pkgName string) ([]*GenerateDirective, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	res := []*GenerateDirective{}
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if !strings.HasPrefix(text, "//go:generate ") && !strings.HasPrefix(text, "//go:generate\t") {
+			continue
+		}
+
+		gd := &GenerateDirective{
+			Dir:     dir,
+			File:    filename,
+			Line:    line,
+			Package: pkgName,
+			Command: strings.TrimSpace(text[len("//go:generate "):]),
+		}
+
+		words, err := splitGenerateArgs(gd.Command)
+		if err != nil {
+			return nil, err
+		}
+		for i, word := range words {
+			words[i] = os.Expand(word, gd.Getenv)
+		}
+		gd.Args = words
+
+		gd.Script = resolveGenerateScript(dir, words)
+		gd.Gogtok = gd.Script != "" && IsGogtokScript(gd.Script)
+
+		res = append(res, gd)
+	}
+	return res, scanner.Err()
+}
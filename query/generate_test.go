@@ -0,0 +1,80 @@
+package query
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSplitGenerateArgs(t *testing.T) {
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{"", []string{}},
+		{"stringer -type=Kind", []string{"stringer", "-type=Kind"}},
+		{"  sh\t./gen.sh  ", []string{"sh", "./gen.sh"}},
+		{`echo "a b" "c\"d" e`, []string{"echo", "a b", `c"d`, "e"}},
+	}
+	for _, tt := range tests {
+		got, err := splitGenerateArgs(tt.line)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitGenerateArgs(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+
+	if _, err := splitGenerateArgs(`echo "a`); err == nil {
+		t.Error("unterminated quoted strings must be rejected")
+	}
+}
+
+func TestGenerateDirectives(t *testing.T) {
+	dir := newTestDir(t, map[string]string{
+		"a.go": `package a
+
+//go:generate ./gen.sh $GOFILE
+//go:generate bash scripts/other.sh
+//go:generate stringer -type=Kind
+// go:generate ignored
+`,
+		"a_test.go":        "package a_test\n\n//go:generate ./gen.sh\n",
+		"gen.sh":           "#!/bin/bash\necho \"// Code generated by './gen.sh'. DO NOT EDIT.\"\n",
+		"scripts/other.sh": "#!/bin/bash\n",
+	})
+
+	directives, err := GenerateDirectives(nil, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type directive struct {
+		file    string
+		line    int
+		args    []string
+		script  string
+		gogtok  bool
+		pkgName string
+	}
+	got := []directive{}
+	for _, gd := range directives {
+		got = append(got, directive{filepath.Base(gd.File), gd.Line, gd.Args, gd.Script, gd.Gogtok, gd.Package})
+	}
+	want := []directive{
+		{"a.go", 3, []string{"./gen.sh", "a.go"}, filepath.Join(dir, "gen.sh"), true, "a"},
+		{"a.go", 4, []string{"bash", "scripts/other.sh"}, filepath.Join(dir, "scripts/other.sh"), false, "a"},
+		{"a.go", 5, []string{"stringer", "-type=Kind"}, "", false, "a"},
+		{"a_test.go", 3, []string{"./gen.sh"}, filepath.Join(dir, "gen.sh"), true, "a"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GenerateDirectives() = %v, want %v", got, want)
+	}
+
+	gd := directives[0]
+	if gd.Getenv("GOLINE") != "3" || gd.Getenv("DOLLAR") != "$" || gd.Getenv("HOME") != os.Getenv("HOME") {
+		t.Errorf("Getenv() returns wrong values")
+	}
+}
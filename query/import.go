@@ -0,0 +1,67 @@
+package query
+
+import (
+	"io"
+	"strings"
+)
+
+// Import is a package to be imported.
+type Import struct {
+	// Name is the package name to import or empty to use the default.
+	Name string
+	Path string
+}
+
+// String renders the import spec such as `name "path"`.
+func (pkg *Import) String() string {
+	b := strings.Builder{}
+	if pkg.Name != "" {
+		b.WriteString(pkg.Name)
+		b.WriteRune(' ')
+	}
+	b.WriteRune('"')
+	b.WriteString(pkg.Path)
+	b.WriteRune('"')
+
+	return b.String()
+}
+
+// ParseImport parses the import spec such as `path`, `"path"` or `name "path"`.
+func ParseImport(s string) (*Import, error) {
+	s = strings.TrimSpace(s)
+
+	pair := strings.SplitN(s, " ", 2)
+	if len(pair) == 1 {
+		return &Import{
+			Path: strings.Trim(s, `"`),
+		}, nil
+	}
+
+	return &Import{
+		Name: pair[0],
+		Path: strings.Trim(pair[1], `"`),
+	}, nil
+}
+
+// RenderImport writes the import declaration.
+// Parentheses are used only if there are multiple packages.
+func RenderImport(w io.Writer, pkgs []*Import) (err error) {
+	switch len(pkgs) {
+	case 0:
+		return nil
+	case 1:
+		io.WriteString(w, "import ")
+		io.WriteString(w, pkgs[0].String())
+		io.WriteString(w, "\n")
+		return nil
+	}
+
+	io.WriteString(w, "import (\n")
+	for _, pkg := range pkgs {
+		io.WriteString(w, "\t")
+		io.WriteString(w, pkg.String())
+		io.WriteString(w, "\n")
+	}
+	io.WriteString(w, ")\n")
+	return nil
+}
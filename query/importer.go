@@ -0,0 +1,94 @@
+package query
+
+import (
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+)
+
+// sourceImporter type-checks imported packages from source like the "source" importer of go/importer,
+// but it selects files with its own build context instead of build.Default.
+type sourceImporter struct {
+	ctxt *build.Context
+	fset *token.FileSet
+	// packages are imported packages keyed by import paths.
+	// A nil package is being imported to detect import cycles.
+	packages map[string]*types.Package
+}
+
+func newSourceImporter(ctxt *build.Context, fset *token.FileSet) *sourceImporter {
+	return &sourceImporter{
+		ctxt:     ctxt,
+		fset:     fset,
+		packages: map[string]*types.Package{},
+	}
+}
+
+// Import imports the package relative to the working directory.
+func (p *sourceImporter) Import(path string) (*types.Package, error) {
+	return p.ImportFrom(path, ".", 0)
+}
+
+// ImportFrom imports the package of the path imported from the directory.
+func (p *sourceImporter) ImportFrom(path, srcDir string, _ types.ImportMode) (*types.Package, error) {
+	if path == "unsafe" {
+		return types.Unsafe, nil
+	}
+
+	absDir, err := filepath.Abs(srcDir)
+	if err != nil {
+		return nil, err
+	}
+	// The go command resolves modules in the directory instead of the working directory
+	ctxt := *p.ctxt
+	ctxt.Dir = absDir
+	bp, err := ctxt.Import(path, absDir, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if pkg, ok := p.packages[bp.ImportPath]; ok {
+		if pkg == nil {
+			return nil, fmt.Errorf("import cycle through package %q", bp.ImportPath)
+		}
+		return pkg, nil
+	}
+	p.packages[bp.ImportPath] = nil
+
+	filenames := append(append([]string{}, bp.GoFiles...), bp.CgoFiles...)
+	files := make([]*ast.File, 0, len(filenames))
+	for _, filename := range filenames {
+		f, err := parser.ParseFile(p.fset, filepath.Join(bp.Dir, filename), nil, 0)
+		if err != nil {
+			delete(p.packages, bp.ImportPath)
+			return nil, err
+		}
+		files = append(files, f)
+	}
+
+	var hardErr error
+	conf := &types.Config{
+		Importer:         p,
+		IgnoreFuncBodies: true,
+		// Cgo files are checked without running cgo
+		FakeImportC: true,
+		Error: func(err error) {
+			if terr, ok := err.(types.Error); hardErr == nil && (!ok || !terr.Soft) {
+				hardErr = err
+			}
+		},
+	}
+	pkg, _ := conf.Check(bp.ImportPath, p.fset, files, nil)
+	if hardErr != nil {
+		// The package may not be fully populated
+		delete(p.packages, bp.ImportPath)
+		return nil, fmt.Errorf("type-checking package %q failed (%v)", bp.ImportPath, hardErr)
+	}
+
+	p.packages[bp.ImportPath] = pkg
+	return pkg, nil
+}
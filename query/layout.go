@@ -0,0 +1,61 @@
+package query
+
+import (
+	"go/types"
+	"sort"
+)
+
+// FieldLayout is a memory layout of a struct field.
+type FieldLayout struct {
+	Name   string
+	Type   types.Type
+	Offset int64
+	Size   int64
+	Align  int64
+	// Padding is the number of bytes after the field.
+	Padding int64
+}
+
+// StructLayout computes offsets, sizes and paddings of the fields.
+func StructLayout(sizes types.Sizes, vars []*types.Var) ([]*FieldLayout, int64) {
+	st := types.NewStruct(vars, nil)
+	offsets := sizes.Offsetsof(vars)
+	total := sizes.Sizeof(st)
+
+	fields := make([]*FieldLayout, len(vars))
+	for i, v := range vars {
+		fields[i] = &FieldLayout{
+			Name:   v.Name(),
+			Type:   v.Type(),
+			Offset: offsets[i],
+			Size:   sizes.Sizeof(v.Type()),
+			Align:  sizes.Alignof(v.Type()),
+		}
+	}
+	for i, f := range fields {
+		end := total
+		if i+1 < len(fields) {
+			end = fields[i+1].Offset
+		}
+		f.Padding = end - f.Offset - f.Size
+	}
+	return fields, total
+}
+
+// OptimalFieldOrder sorts fields to minimize padding.
+// Zero-size fields are placed first because a trailing zero-size field is padded.
+func OptimalFieldOrder(sizes types.Sizes, vars []*types.Var) []*types.Var {
+	res := append([]*types.Var{}, vars...)
+	sort.SliceStable(res, func(i, j int) bool {
+		si, sj := sizes.Sizeof(res[i].Type()), sizes.Sizeof(res[j].Type())
+		if (si == 0) != (sj == 0) {
+			return si == 0
+		}
+		ai, aj := sizes.Alignof(res[i].Type()), sizes.Alignof(res[j].Type())
+		if ai != aj {
+			return ai > aj
+		}
+		return si > sj
+	})
+	return res
+}
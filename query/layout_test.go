@@ -0,0 +1,39 @@
+package query
+
+import (
+	"go/types"
+	"testing"
+)
+
+func TestStructLayout(t *testing.T) {
+	sizes := types.SizesFor("gc", "amd64")
+	vars := []*types.Var{
+		types.NewField(0, nil, "a", types.Typ[types.Bool], false),
+		types.NewField(0, nil, "b", types.Typ[types.Int64], false),
+		types.NewField(0, nil, "c", types.Typ[types.Int16], false),
+		types.NewField(0, nil, "d", types.NewStruct(nil, nil), false),
+	}
+
+	fields, total := StructLayout(sizes, vars[:3])
+	if total != 24 {
+		t.Errorf("total = %d, want 24", total)
+	}
+	want := []struct{ offset, size, padding int64 }{{0, 1, 7}, {8, 8, 0}, {16, 2, 6}}
+	for i, f := range fields {
+		if f.Offset != want[i].offset || f.Size != want[i].size || f.Padding != want[i].padding {
+			t.Errorf("%s = offset %d, size %d, padding %d, want %v", f.Name, f.Offset, f.Size, f.Padding, want[i])
+		}
+	}
+
+	optimal := OptimalFieldOrder(sizes, vars)
+	names := ""
+	for _, v := range optimal {
+		names += v.Name()
+	}
+	if names != "dbca" {
+		t.Errorf("OptimalFieldOrder() = %s, want dbca", names)
+	}
+	if _, total := StructLayout(sizes, optimal[1:]); total != 16 {
+		t.Errorf("optimal total = %d, want 16", total)
+	}
+}
@@ -0,0 +1,192 @@
+// Package query provides functions to query declarations of Go source files
+// with the same semantics as gogtok commands.
+package query
+
+import (
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Package is a parsed and type-checked package.
+type Package struct {
+	Dir   string
+	Fset  *token.FileSet
+	Files []*ast.File
+	Types *types.Package
+	Info  *types.Info
+}
+
+// File returns the parsed file of the filename in the package.
+func (pkg *Package) File(filename string) *ast.File {
+	absFilename, err := filepath.Abs(filename)
+	if err != nil {
+		return nil
+	}
+
+	for _, f := range pkg.Files {
+		absPath, err := filepath.Abs(pkg.Fset.Position(f.Pos()).Filename)
+		if err == nil && absPath == absFilename {
+			return f
+		}
+	}
+	return nil
+}
+
+// Filenames returns names of the files in the package.
+func (pkg *Package) Filenames() []string {
+	res := make([]string, len(pkg.Files))
+	for i, f := range pkg.Files {
+		res[i] = pkg.Fset.Position(f.Pos()).Filename
+	}
+	return res
+}
+
+// LoadConfig is options to load packages.
+type LoadConfig struct {
+	// Tests includes test files of the packages (not external test packages).
+	Tests bool
+	// BuildTags are added to build.Default.BuildTags to select files of the packages and their imports.
+	BuildTags []string
+}
+
+// BuildContext returns a copy of build.Default with the build tags.
+func (cfg *LoadConfig) BuildContext() *build.Context {
+	ctxt := build.Default
+	if cfg != nil {
+		ctxt.BuildTags = append(append([]string{}, ctxt.BuildTags...), cfg.BuildTags...)
+	}
+	return &ctxt
+}
+
+// ParseFile parses the file with comments.
+func ParseFile(fset *token.FileSet, filename string) (*ast.File, error) {
+	return parser.ParseFile(fset, filename, nil, parser.ParseComments)
+}
+
+// ExpandPatterns expands patterns such as "./..." into directories.
+// "." is used if patterns are empty.
+func ExpandPatterns(patterns []string) ([]string, error) {
+	if len(patterns) == 0 {
+		patterns = []string{"."}
+	}
+
+	dirs := []string{}
+	for _, pattern := range patterns {
+		if !strings.HasSuffix(pattern, "/...") && pattern != "..." {
+			dirs = append(dirs, pattern)
+			continue
+		}
+
+		root := strings.TrimSuffix(strings.TrimSuffix(pattern, "..."), "/")
+		if root == "" {
+			root = "."
+		}
+
+		err := filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if !info.IsDir() {
+				return nil
+			}
+
+			name := info.Name()
+			if p != root && (name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+				return filepath.SkipDir
+			}
+
+			if hasGoFiles(p) {
+				dirs = append(dirs, p)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return dirs, nil
+}
+
+func hasGoFiles(dir string) bool {
+	matches, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	return err == nil && len(matches) > 0
+}
+
+// Load parses and type-checks packages in the directories.
+// Type errors are ignored so that incomplete packages can be queried.
+func Load(cfg *LoadConfig, dirs ...string) ([]*Package, error) {
+	if cfg == nil {
+		cfg = &LoadConfig{}
+	}
+
+	fset := token.NewFileSet()
+	imp := newSourceImporter(cfg.BuildContext(), fset)
+
+	pkgs := make([]*Package, 0, len(dirs))
+	for _, dir := range dirs {
+		pkg, err := loadPackage(cfg, fset, imp, dir)
+		if err != nil {
+			return nil, err
+		}
+		pkgs = append(pkgs, pkg)
+	}
+	return pkgs, nil
+}
+
+func loadPackage(cfg *LoadConfig, fset *token.FileSet, imp types.Importer, dir string) (*Package, error) {
+	buildPkg, err := cfg.BuildContext().ImportDir(dir, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	filenames := append([]string{}, buildPkg.GoFiles...)
+	filenames = append(filenames, buildPkg.CgoFiles...)
+	if cfg.Tests {
+		filenames = append(filenames, buildPkg.TestGoFiles...)
+	}
+
+	files := make([]*ast.File, 0, len(filenames))
+	for _, filename := range filenames {
+		f, err := ParseFile(fset, filepath.Join(dir, filename))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+
+	pkgPath, err := PackagePath(dir)
+	if err != nil {
+		logrus.WithError(err).Debug("Failed to get package path. Package name will be used.")
+		pkgPath = buildPkg.Name
+	}
+
+	info := &types.Info{
+		Types:      map[ast.Expr]types.TypeAndValue{},
+		Defs:       map[*ast.Ident]types.Object{},
+		Uses:       map[*ast.Ident]types.Object{},
+		Selections: map[*ast.SelectorExpr]*types.Selection{},
+	}
+	conf := &types.Config{
+		Importer: imp,
+		Error: func(err error) {
+			logrus.WithError(err).Debug("Type error")
+		},
+	}
+	pkg, _ := conf.Check(pkgPath, fset, files, info)
+
+	return &Package{
+		Dir:   dir,
+		Fset:  fset,
+		Files: files,
+		Types: pkg,
+		Info:  info,
+	}, nil
+}
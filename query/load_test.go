@@ -0,0 +1,78 @@
+package query
+
+import (
+	"go/build"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newTestDir writes the files into a temporary directory and returns it.
+func newTestDir(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, content := range files {
+		filename := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filename, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLoadBuildTags(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip(err)
+	}
+
+	dir := newTestDir(t, map[string]string{
+		"go.mod":         "module example.com/test\n\ngo 1.18\n",
+		"main.go":        "package main\n\nimport \"example.com/test/dep\"\n\nvar _ = dep.Value\n",
+		"dep/default.go": "//go:build !custom\n\npackage dep\n\nconst Value = 1\n",
+		"dep/custom.go":  "//go:build custom\n\npackage dep\n\nconst Value = \"custom\"\n",
+		"tagged.go":      "//go:build custom\n\npackage main\n\nimport \"example.com/test/dep\"\n\nvar Tagged = dep.Value\n",
+	})
+
+	defaultTags := strings.Join(build.Default.BuildTags, ",")
+	tests := []struct {
+		tags   []string
+		files  int
+		value  string
+		tagged bool
+	}{
+		{nil, 1, "untyped int", false},
+		{[]string{"custom"}, 2, "untyped string", true},
+	}
+	for _, tt := range tests {
+		pkgs, err := Load(&LoadConfig{BuildTags: tt.tags}, dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pkg := pkgs[0]
+		if len(pkg.Files) != tt.files {
+			t.Errorf("tags %v: %d files are loaded, want %d", tt.tags, len(pkg.Files), tt.files)
+		}
+
+		dep := pkg.Types.Imports()
+		if len(dep) != 1 {
+			t.Fatalf("tags %v: imports = %v", tt.tags, dep)
+		}
+		if got := dep[0].Scope().Lookup("Value").Type().String(); got != tt.value {
+			t.Errorf("tags %v: type of dep.Value = %s, want %s", tt.tags, got, tt.value)
+		}
+		if got := pkg.Types.Scope().Lookup("Tagged") != nil; got != tt.tagged {
+			t.Errorf("tags %v: Tagged is declared = %v", tt.tags, got)
+		}
+	}
+
+	if strings.Join(build.Default.BuildTags, ",") != defaultTags {
+		t.Errorf("build.Default.BuildTags is changed: %v", build.Default.BuildTags)
+	}
+}
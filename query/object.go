@@ -0,0 +1,159 @@
+package query
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+)
+
+// ObjectQuery is a name of objects such as "Name", "pkg.Name", "Type.Member" or "path/to/pkg.Type.Member".
+type ObjectQuery struct {
+	PkgPath string
+	Names   []string
+}
+
+// ParseObjectQuery parses the name of objects.
+func ParseObjectQuery(s string) *ObjectQuery {
+	q := &ObjectQuery{}
+
+	if i := strings.LastIndex(s, "/"); i >= 0 {
+		rest := strings.Split(s[i+1:], ".")
+		q.PkgPath = s[:i+1] + rest[0]
+		q.Names = rest[1:]
+		return q
+	}
+
+	q.Names = strings.Split(s, ".")
+	return q
+}
+
+// Resolve finds objects matching the query in packages and their imports.
+func (q *ObjectQuery) Resolve(pkgs []*Package) []types.Object {
+	res := []types.Object{}
+	visited := map[*types.Package]bool{}
+
+	var visit func(pkg *types.Package)
+	visit = func(pkg *types.Package) {
+		if pkg == nil || visited[pkg] {
+			return
+		}
+		visited[pkg] = true
+
+		res = append(res, q.lookup(pkg)...)
+		for _, imported := range pkg.Imports() {
+			visit(imported)
+		}
+	}
+	for _, pkg := range pkgs {
+		visit(pkg.Types)
+	}
+
+	if q.PkgPath == "" && len(q.Names) == 1 {
+		if obj := types.Universe.Lookup(q.Names[0]); obj != nil {
+			res = append(res, obj)
+		}
+	}
+	return res
+}
+
+func (q *ObjectQuery) lookup(pkg *types.Package) []types.Object {
+	candidates := [][]string{}
+	switch {
+	case q.PkgPath != "":
+		if pkg.Path() == q.PkgPath {
+			candidates = append(candidates, q.Names)
+		}
+	default:
+		candidates = append(candidates, q.Names)
+		if len(q.Names) > 1 && (pkg.Path() == q.Names[0] || pkg.Name() == q.Names[0]) {
+			candidates = append(candidates, q.Names[1:])
+		}
+	}
+
+	res := []types.Object{}
+	for _, names := range candidates {
+		if obj := lookupNames(pkg, names); obj != nil {
+			res = append(res, obj)
+		}
+	}
+	return res
+}
+
+func lookupNames(pkg *types.Package, names []string) types.Object {
+	switch len(names) {
+	case 1:
+		return pkg.Scope().Lookup(names[0])
+	case 2:
+		typeName, ok := pkg.Scope().Lookup(names[0]).(*types.TypeName)
+		if !ok {
+			return nil
+		}
+		obj, _, _ := types.LookupFieldOrMethod(types.NewPointer(typeName.Type()), true, pkg, names[1])
+		return obj
+	}
+	return nil
+}
+
+// ObjectKey identifies an object by its declared position.
+// Packages imported by type-checked packages are checked again, so objects cannot be compared directly.
+func ObjectKey(fset *token.FileSet, obj types.Object) string {
+	if obj.Pkg() == nil || !obj.Pos().IsValid() {
+		return obj.Name()
+	}
+	pos := fset.Position(obj.Pos())
+	return obj.Pkg().Path() + "@" + pos.Filename + ":" + strconv.Itoa(pos.Offset)
+}
+
+func objectKeySet(fset *token.FileSet, objs []types.Object) map[string]bool {
+	keys := make(map[string]bool, len(objs))
+	for _, obj := range objs {
+		keys[ObjectKey(fset, obj)] = true
+	}
+	return keys
+}
+
+// inspectWithStack calls fn with ancestors of each node.
+func inspectWithStack(node ast.Node, fn func(n ast.Node, stack []ast.Node)) {
+	stack := []ast.Node{}
+	ast.Inspect(node, func(n ast.Node) bool {
+		if n == nil {
+			stack = stack[:len(stack)-1]
+			return false
+		}
+		fn(n, stack)
+		stack = append(stack, n)
+		return true
+	})
+}
+
+// enclosingFuncName returns the name of the function declaration in the stack.
+func enclosingFuncName(stack []ast.Node) string {
+	for _, n := range stack {
+		fnDecl, ok := n.(*ast.FuncDecl)
+		if !ok {
+			continue
+		}
+		if fnDecl.Recv != nil && len(fnDecl.Recv.List) > 0 {
+			return ReceiverTypeName(fnDecl.Recv.List[0].Type) + "." + fnDecl.Name.Name
+		}
+		return fnDecl.Name.Name
+	}
+	return ""
+}
+
+// ReceiverTypeName returns the type name of the receiver such as "T" for "*T".
+func ReceiverTypeName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return ReceiverTypeName(t.X)
+	case *ast.ParenExpr:
+		return ReceiverTypeName(t.X)
+	case *ast.IndexExpr:
+		return ReceiverTypeName(t.X)
+	case *ast.Ident:
+		return t.Name
+	}
+	return ""
+}
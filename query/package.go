@@ -0,0 +1,132 @@
+package query
+
+import (
+	"fmt"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/sirkon/goproxy/gomod"
+)
+
+// PackageName returns the name of the package in the directory.
+// Names of external test packages are ignored.
+func PackageName(dir string) (string, error) {
+	fileInfos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+
+	for _, fileInfo := range fileInfos {
+		fileName := path.Join(dir, fileInfo.Name())
+		if fileInfo.IsDir() || !strings.HasSuffix(fileName, ".go") {
+			continue
+		}
+
+		reader, err := os.Open(fileName)
+		if err != nil {
+			return "", err
+		}
+
+		fset := token.NewFileSet()
+		f, err := parser.ParseFile(fset, fileName, reader, parser.PackageClauseOnly)
+		if err != nil {
+			return "", err
+		}
+
+		packageName := f.Name.Name
+		if strings.HasSuffix(packageName, "_test") {
+			continue
+		}
+
+		return packageName, nil
+	}
+
+	return "", fmt.Errorf("no .go files: %s", dir)
+}
+
+// PackagePath returns the import path of the package in the directory.
+// It respects GO111MODULE and go.mod in parent directories.
+func PackagePath(dir string) (string, error) {
+	absPath, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+
+	gopath := os.Getenv("GOPATH")
+	if gopath == "" {
+		gopath = build.Default.GOPATH
+	}
+	goSRCPath := path.Join(gopath, "src")
+
+	go111Module := os.Getenv("GO111MODULE")
+	if go111Module != "on" && go111Module != "off" {
+		if strings.HasPrefix(absPath, goSRCPath) {
+			go111Module = "off"
+		} else {
+			go111Module = "on"
+		}
+	}
+
+	if go111Module == "off" {
+		if !strings.HasPrefix(absPath, goSRCPath) {
+			return "", fmt.Errorf("%s is not in GOPATH", absPath)
+		}
+
+		return absPath[len(goSRCPath)+1:], nil
+	}
+
+	filePath, err := FindGoModFile(absPath)
+	if err != nil {
+		return "", err
+	}
+
+	f, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+
+	b, err := ioutil.ReadAll(f)
+	if err != nil {
+		return "", err
+	}
+
+	mod, err := gomod.Parse(filePath, b)
+	if err != nil {
+		return "", err
+	}
+
+	rel, err := filepath.Rel(path.Dir(filePath), absPath)
+	if err != nil {
+		return "", err
+	}
+	if rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is not in %s", absPath, mod.Name)
+	}
+
+	return path.Join(mod.Name, filepath.ToSlash(rel)), nil
+}
+
+// FindGoModFile finds go.mod in the directory or its parents.
+func FindGoModFile(absDir string) (string, error) {
+	for {
+		filePath := path.Join(absDir, "go.mod")
+
+		info, err := os.Stat(filePath)
+		if err == nil && !info.IsDir() {
+			return filePath, nil
+		} else if !os.IsNotExist(err) {
+			return "", err
+		}
+
+		absDir = path.Dir(absDir)
+		if absDir == "/" {
+			return "", os.ErrNotExist
+		}
+	}
+}
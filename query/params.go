@@ -0,0 +1,158 @@
+package query
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strconv"
+	"strings"
+)
+
+// Param is a parameter or a result of a function.
+type Param struct {
+	// Name is the declared name. It may be empty or "_".
+	Name string
+	// Type is the type such as "int" or "...string".
+	Type     string
+	Index    int
+	Variadic bool
+	// Ident is the name or the synthesized name such as "p0" or "r1" if it is unnamed.
+	// Synthesized names are followed by underscores such as "p0_" if the signature or the receiver already uses them.
+	Ident string
+}
+
+// Arg returns an expression to pass the parameter such as "p0" or "opts...".
+func (p *Param) Arg() string {
+	if p.Variadic {
+		return p.Ident + "..."
+	}
+	return p.Ident
+}
+
+// Params flattens the field list of parameters.
+// prefix is used to synthesize names of unnamed parameters.
+// Synthesized names avoid names declared in fields and reserved such as the other list of the signature.
+func Params(fields *ast.FieldList, prefix string, reserved ...*ast.FieldList) []*Param {
+	res := []*Param{}
+	if fields == nil {
+		return res
+	}
+
+	used := map[string]bool{}
+	for _, list := range append(reserved, fields) {
+		if list == nil {
+			continue
+		}
+		for _, field := range list.List {
+			for _, nameIdent := range field.Names {
+				used[nameIdent.Name] = true
+			}
+		}
+	}
+
+	index := 0
+	for _, field := range fields.List {
+		_, variadic := field.Type.(*ast.Ellipsis)
+		names := []string{""}
+		if len(field.Names) > 0 {
+			names = names[:0]
+			for _, nameIdent := range field.Names {
+				names = append(names, nameIdent.Name)
+			}
+		}
+
+		for _, name := range names {
+			ident := name
+			if name == "" || name == "_" {
+				ident = prefix + strconv.Itoa(index)
+				for used[ident] {
+					ident += "_"
+				}
+				used[ident] = true
+			}
+
+			res = append(res, &Param{
+				Name:     name,
+				Type:     TypeString(field.Type),
+				Index:    index,
+				Variadic: variadic,
+				Ident:    ident,
+			})
+			index++
+		}
+	}
+	return res
+}
+
+// Signature returns parameters and results of the function found by FindFunc.
+// Unnamed parameters are named "p0", "p1"... and unnamed results are named "r0", "r1"...
+// Synthesized names avoid the receiver name.
+func Signature(f *ast.File, name string) (params []*Param, results []*Param, err error) {
+	fnType, recv := FindFunc(f, name)
+	if fnType == nil {
+		return nil, nil, fmt.Errorf("function not found: %s", name)
+	}
+	return Params(fnType.Params, "p", fnType.Results, recv), Params(fnType.Results, "r", fnType.Params, recv), nil
+}
+
+// FindFuncType finds a function "Name", a method "Type.Method", an interface method "Interface.Method"
+// or a function type "Type" in the file.
+func FindFuncType(f *ast.File, name string) *ast.FuncType {
+	fnType, _ := FindFunc(f, name)
+	return fnType
+}
+
+// FindFunc finds the function like FindFuncType and returns the receiver if it is a method declaration.
+func FindFunc(f *ast.File, name string) (*ast.FuncType, *ast.FieldList) {
+	recvName, funcName := "", name
+	if i := strings.Index(name, "."); i >= 0 {
+		recvName, funcName = name[:i], name[i+1:]
+	}
+
+	for _, decl := range f.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			if decl.Name.Name != funcName {
+				continue
+			}
+			hasRecv := decl.Recv != nil && len(decl.Recv.List) > 0
+			if !hasRecv && recvName == "" {
+				return decl.Type, nil
+			}
+			if hasRecv && ReceiverTypeName(decl.Recv.List[0].Type) == recvName {
+				return decl.Type, decl.Recv
+			}
+		case *ast.GenDecl:
+			if decl.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range decl.Specs {
+				typeSpec, ok := spec.(*ast.TypeSpec)
+				if !ok {
+					continue
+				}
+
+				switch t := typeSpec.Type.(type) {
+				case *ast.FuncType:
+					if recvName == "" && typeSpec.Name.Name == funcName {
+						return t, nil
+					}
+				case *ast.InterfaceType:
+					if recvName != typeSpec.Name.Name {
+						continue
+					}
+					for _, method := range t.Methods.List {
+						for _, nameIdent := range method.Names {
+							if nameIdent.Name == funcName {
+								if fnType, ok := method.Type.(*ast.FuncType); ok {
+									return fnType, nil
+								}
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+	return nil, nil
+}
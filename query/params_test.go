@@ -0,0 +1,90 @@
+package query
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"testing"
+)
+
+func paramArgs(params []*Param) []string {
+	res := []string{}
+	for _, p := range params {
+		res = append(res, p.Arg())
+	}
+	return res
+}
+
+func TestParams(t *testing.T) {
+	tests := []struct {
+		src     string
+		params  []string
+		results []string
+	}{
+		{"func(int, string) error", []string{"p0", "p1"}, []string{"r0"}},
+		{"func(a, _ int, opts ...string) (n int, err error)", []string{"a", "p1", "opts..."}, []string{"n", "err"}},
+		{"func(int, string) (p0 int, p0_ error)", []string{"p0__", "p1"}, []string{"p0", "p0_"}},
+		{"func(r0 int) (int, error)", []string{"r0"}, []string{"r0_", "r1"}},
+		{"func()", []string{}, []string{}},
+	}
+
+	for _, tt := range tests {
+		expr, err := parser.ParseExpr(tt.src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fnType := expr.(*ast.FuncType)
+
+		if got := paramArgs(Params(fnType.Params, "p", fnType.Results)); !reflect.DeepEqual(got, tt.params) {
+			t.Errorf("params of %s = %v, want %v", tt.src, got, tt.params)
+		}
+		if got := paramArgs(Params(fnType.Results, "r", fnType.Params)); !reflect.DeepEqual(got, tt.results) {
+			t.Errorf("results of %s = %v, want %v", tt.src, got, tt.results)
+		}
+	}
+}
+
+func TestSignature(t *testing.T) {
+	f, err := parser.ParseFile(token.NewFileSet(), "p.go", `package p
+
+type T struct{}
+
+func (p0 *T) Method(int, string) (r0 error) { return nil }
+
+func Func(int) {}
+
+type I interface {
+	Method(p0 int, _ string) error
+}
+`, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		params  []string
+		results []string
+	}{
+		{"T.Method", []string{"p0_", "p1"}, []string{"r0"}},
+		{"Func", []string{"p0"}, []string{}},
+		{"I.Method", []string{"p0", "p1"}, []string{"r0"}},
+	}
+	for _, tt := range tests {
+		params, results, err := Signature(f, tt.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := paramArgs(params); !reflect.DeepEqual(got, tt.params) {
+			t.Errorf("params of %s = %v, want %v", tt.name, got, tt.params)
+		}
+		if got := paramArgs(results); !reflect.DeepEqual(got, tt.results) {
+			t.Errorf("results of %s = %v, want %v", tt.name, got, tt.results)
+		}
+	}
+
+	if _, _, err := Signature(f, "T.Missing"); err == nil {
+		t.Error("Signature() of a missing method must fail")
+	}
+}
@@ -0,0 +1,152 @@
+package query
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+)
+
+// Ref is a use of an object.
+type Ref struct {
+	Pos token.Position
+	// Name is the identifier referring the object.
+	Name string
+	// Package is the path of the package using the object.
+	Package string
+	// Func is the name of the function declaration using the object such as "Func" or "Type.Method".
+	Func string
+	// Kind is one of "call", "conversion", "composite", "key", "field-type", "embed",
+	// "param", "result", "receiver", "var-type", "type-decl", "assertion", "type" and "value".
+	Kind string
+}
+
+// Refs returns uses of the objects in the packages.
+func Refs(pkgs []*Package, objs []types.Object) []*Ref {
+	res := []*Ref{}
+	for _, pkg := range pkgs {
+		keys := objectKeySet(pkg.Fset, objs)
+		for _, f := range pkg.Files {
+			inspectWithStack(f, func(n ast.Node, stack []ast.Node) {
+				ident, ok := n.(*ast.Ident)
+				if !ok {
+					return
+				}
+				obj := pkg.Info.Uses[ident]
+				if obj == nil || !keys[ObjectKey(pkg.Fset, obj)] {
+					return
+				}
+
+				res = append(res, &Ref{
+					Pos:     pkg.Fset.Position(ident.Pos()),
+					Name:    ident.Name,
+					Package: pkg.Types.Path(),
+					Func:    enclosingFuncName(stack),
+					Kind:    refKind(obj, ident, stack),
+				})
+			})
+		}
+	}
+	return res
+}
+
+// refKind classifies the use of the identifier by its ancestors.
+func refKind(obj types.Object, ident *ast.Ident, stack []ast.Node) string {
+	var node ast.Node = ident
+	i := len(stack) - 1
+	if i >= 0 {
+		if sel, ok := stack[i].(*ast.SelectorExpr); ok && sel.Sel == ident {
+			node = sel
+			i--
+		}
+	}
+
+	_, isType := obj.(*types.TypeName)
+
+	// Skip type expressions wrapping the type
+	for isType && i >= 0 && isTypeWrapper(stack[i]) {
+		node = stack[i]
+		i--
+	}
+	if i < 0 {
+		return "value"
+	}
+
+	switch parent := stack[i].(type) {
+	case *ast.CallExpr:
+		if parent.Fun == node {
+			if isType {
+				return "conversion"
+			}
+			return "call"
+		}
+	case *ast.CompositeLit:
+		if parent.Type == node {
+			return "composite"
+		}
+	case *ast.KeyValueExpr:
+		if parent.Key == node && i > 0 {
+			if _, ok := stack[i-1].(*ast.CompositeLit); ok {
+				if v, ok := obj.(*types.Var); ok && v.IsField() {
+					return "key"
+				}
+			}
+		}
+	case *ast.Field:
+		if parent.Type == node {
+			return fieldRefKind(parent, stack[:i])
+		}
+	case *ast.ValueSpec:
+		if parent.Type == node {
+			return "var-type"
+		}
+	case *ast.TypeSpec:
+		if parent.Type == node {
+			return "type-decl"
+		}
+	case *ast.TypeAssertExpr:
+		if parent.Type == node {
+			return "assertion"
+		}
+	}
+
+	if isType {
+		return "type"
+	}
+	return "value"
+}
+
+func isTypeWrapper(n ast.Node) bool {
+	switch n.(type) {
+	case *ast.StarExpr, *ast.ArrayType, *ast.MapType, *ast.ChanType, *ast.Ellipsis, *ast.ParenExpr:
+		return true
+	}
+	return false
+}
+
+func fieldRefKind(field *ast.Field, stack []ast.Node) string {
+	if len(stack) < 2 {
+		return "type"
+	}
+	fieldList, ok := stack[len(stack)-1].(*ast.FieldList)
+	if !ok {
+		return "type"
+	}
+
+	switch owner := stack[len(stack)-2].(type) {
+	case *ast.StructType:
+		if len(field.Names) == 0 {
+			return "embed"
+		}
+		return "field-type"
+	case *ast.InterfaceType:
+		return "embed"
+	case *ast.FuncType:
+		if owner.Results == fieldList {
+			return "result"
+		}
+		return "param"
+	case *ast.FuncDecl:
+		return "receiver"
+	}
+	return "type"
+}
@@ -0,0 +1,219 @@
+package query
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Test is a test function or a subtest.
+type Test struct {
+	Pos     token.Position
+	Package string
+	// Name is the function name or the subtest name such as "TestX/sub".
+	Name string
+	// Kind is one of "main", "test", "benchmark", "fuzz", "example" and "subtest".
+	Kind string
+	// Err is a message if the signature is invalid.
+	Err string
+}
+
+// Valid reports whether the signature is valid.
+func (t *Test) Valid() bool {
+	return t.Err == ""
+}
+
+// Tests returns test functions in the test file.
+// Subtests run with literal names are also returned if subtests is true.
+func Tests(fset *token.FileSet, f *ast.File, subtests bool) []*Test {
+	res := []*Test{}
+	testingName := testingPackageName(f)
+
+	for _, decl := range f.Decls {
+		fnDecl, ok := decl.(*ast.FuncDecl)
+		if !ok || fnDecl.Recv != nil {
+			continue
+		}
+
+		for _, tk := range testKinds {
+			if !isTestName(fnDecl.Name.Name, tk.prefix, tk.exact) {
+				continue
+			}
+			// TestMain(*testing.T) is a test like "go test"
+			if tk.kind == "main" && validateTestSignature(fnDecl, testingName, "T") == "" {
+				continue
+			}
+
+			res = append(res, &Test{
+				Pos:     fset.Position(fnDecl.Pos()),
+				Package: f.Name.Name,
+				Name:    fnDecl.Name.Name,
+				Kind:    tk.kind,
+				Err:     validateTestSignature(fnDecl, testingName, tk.paramType),
+			})
+
+			params := fnDecl.Type.Params
+			if subtests && tk.paramType != "" && params.NumFields() == 1 && len(params.List[0].Names) == 1 {
+				forEachSubtest(fnDecl.Body, params.List[0].Names[0].Name, fnDecl.Name.Name, func(name string, call *ast.CallExpr) {
+					res = append(res, &Test{
+						Pos:     fset.Position(call.Pos()),
+						Package: f.Name.Name,
+						Name:    name,
+						Kind:    "subtest",
+					})
+				})
+			}
+			break
+		}
+	}
+	return res
+}
+
+// testKinds are prefixes of test functions and types of their parameter.
+// TestMain must be the exact name, so TestMainPage is a test.
+// Only TestMain with *testing.M is main, and TestMain with *testing.T is a test.
+var testKinds = []struct {
+	kind      string
+	prefix    string
+	exact     bool
+	paramType string
+}{
+	{"main", "TestMain", true, "M"},
+	{"test", "Test", false, "T"},
+	{"benchmark", "Benchmark", false, "B"},
+	{"fuzz", "Fuzz", false, "F"},
+	{"example", "Example", false, ""},
+}
+
+// isTestName reports whether the name has the prefix followed by a non-lowercase letter like "go test".
+// The name must equal the prefix if exact is true.
+func isTestName(name, prefix string, exact bool) bool {
+	if exact {
+		return name == prefix
+	}
+	if !strings.HasPrefix(name, prefix) {
+		return false
+	}
+	if len(name) == len(prefix) {
+		return true
+	}
+	r, _ := utf8.DecodeRuneInString(name[len(prefix):])
+	return !unicode.IsLower(r)
+}
+
+// testingPackageName returns the name to refer "testing" package in the file.
+func testingPackageName(f *ast.File) string {
+	for _, spec := range f.Imports {
+		p, err := strconv.Unquote(spec.Path.Value)
+		if err != nil || p != "testing" {
+			continue
+		}
+		if spec.Name == nil {
+			return "testing"
+		}
+		if spec.Name.Name == "." {
+			return ""
+		}
+		return spec.Name.Name
+	}
+	return "testing"
+}
+
+func isTestingType(expr ast.Expr, testingName, typeName string) bool {
+	star, ok := expr.(*ast.StarExpr)
+	if !ok {
+		return false
+	}
+	switch t := star.X.(type) {
+	case *ast.Ident:
+		return testingName == "" && t.Name == typeName
+	case *ast.SelectorExpr:
+		x, ok := t.X.(*ast.Ident)
+		return ok && x.Name == testingName && t.Sel.Name == typeName
+	}
+	return false
+}
+
+// validateTestSignature returns a message if the signature is invalid for the kind.
+func validateTestSignature(fnDecl *ast.FuncDecl, testingName, paramType string) string {
+	fnType := fnDecl.Type
+	if fnType.TypeParams != nil && fnType.TypeParams.NumFields() > 0 {
+		return "must not have type parameters"
+	}
+	if fnType.Results.NumFields() != 0 {
+		return "must not have results"
+	}
+
+	if paramType == "" {
+		if fnType.Params.NumFields() != 0 {
+			return "must not have parameters"
+		}
+		return ""
+	}
+
+	params := fnType.Params
+	if params.NumFields() != 1 || !isTestingType(params.List[0].Type, testingName, paramType) {
+		return fmt.Sprintf("must have a single parameter of type *testing.%s", paramType)
+	}
+	return ""
+}
+
+// rewriteSubtestName rewrites the name like testing.T.Run.
+func rewriteSubtestName(s string) string {
+	b := strings.Builder{}
+	for _, r := range s {
+		switch {
+		case unicode.IsSpace(r):
+			b.WriteRune('_')
+		case !strconv.IsPrint(r):
+			b.WriteString(strconv.QuoteRune(r))
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// forEachSubtest finds "t.Run" calls with literal names in the body.
+func forEachSubtest(body *ast.BlockStmt, paramName, prefix string, fn func(name string, call *ast.CallExpr)) {
+	if body == nil || paramName == "" || paramName == "_" {
+		return
+	}
+
+	ast.Inspect(body, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || len(call.Args) != 2 {
+			return true
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok || sel.Sel.Name != "Run" {
+			return true
+		}
+		if x, ok := sel.X.(*ast.Ident); !ok || x.Name != paramName {
+			return true
+		}
+		lit, ok := call.Args[0].(*ast.BasicLit)
+		if !ok || lit.Kind != token.STRING {
+			return true
+		}
+		s, err := strconv.Unquote(lit.Value)
+		if err != nil {
+			return true
+		}
+
+		name := prefix + "/" + rewriteSubtestName(s)
+		fn(name, call)
+
+		if fnLit, ok := call.Args[1].(*ast.FuncLit); ok {
+			params := fnLit.Type.Params
+			if params.NumFields() == 1 && len(params.List[0].Names) == 1 {
+				forEachSubtest(fnLit.Body, params.List[0].Names[0].Name, name, fn)
+			}
+		}
+		return false
+	})
+}
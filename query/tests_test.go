@@ -0,0 +1,80 @@
+package query
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+)
+
+type wantTest struct {
+	name  string
+	kind  string
+	valid bool
+}
+
+func checkTests(t *testing.T, src string, want []wantTest) {
+	t.Helper()
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "p_test.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := Tests(fset, f, true)
+	if len(got) != len(want) {
+		t.Fatalf("len(Tests()) = %d, want %d", len(got), len(want))
+	}
+	for i, w := range want {
+		if got[i].Name != w.name || got[i].Kind != w.kind || got[i].Valid() != w.valid {
+			t.Errorf("Tests()[%d] = %s %s %v (%s), want %s %s %v", i, got[i].Name, got[i].Kind, got[i].Valid(), got[i].Err, w.name, w.kind, w.valid)
+		}
+	}
+}
+
+func TestTests(t *testing.T) {
+	checkTests(t, `package p
+
+import "testing"
+
+func TestMain(m *testing.M) {}
+
+func TestMainPage(t *testing.T) {
+	t.Run("sub", func(t *testing.T) {})
+}
+
+func Testify(t *testing.T) {}
+
+func BenchmarkX(b *testing.B) {}
+
+func FuzzX(f *testing.F) {}
+
+func Example() {}
+
+func TestBad(b *testing.B) {}
+`, []wantTest{
+		{"TestMain", "main", true},
+		{"TestMainPage", "test", true},
+		{"TestMainPage/sub", "subtest", true},
+		{"BenchmarkX", "benchmark", true},
+		{"FuzzX", "fuzz", true},
+		{"Example", "example", true},
+		{"TestBad", "test", false},
+	})
+}
+
+func TestTestsMain(t *testing.T) {
+	tests := []struct {
+		src  string
+		want wantTest
+	}{
+		{"func TestMain(m *testing.M) {}", wantTest{"TestMain", "main", true}},
+		{"func TestMain(t *testing.T) {}", wantTest{"TestMain", "test", true}},
+		{"func TestMain(b *testing.B) {}", wantTest{"TestMain", "main", false}},
+		{"func TestMain() {}", wantTest{"TestMain", "main", false}},
+		{"func TestMain(t *testing.T) int { return 0 }", wantTest{"TestMain", "main", false}},
+	}
+	for _, tt := range tests {
+		checkTests(t, "package p\n\nimport \"testing\"\n\n"+tt.src+"\n", []wantTest{tt.want})
+	}
+}
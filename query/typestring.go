@@ -0,0 +1,165 @@
+package query
+
+import (
+	"go/ast"
+	"go/printer"
+	"go/token"
+	"strings"
+)
+
+// TypeString renders the type expression.
+func TypeString(expr ast.Expr) string {
+	b := &strings.Builder{}
+	b.Grow(int(expr.End()) - int(expr.Pos()))
+	appendExpr(b, expr)
+	return b.String()
+}
+
+func appendExpr(b *strings.Builder, expr ast.Expr) {
+	switch t := expr.(type) {
+	case nil:
+	case *ast.Ident:
+		b.WriteString(t.Name)
+	case *ast.SelectorExpr:
+		appendExpr(b, t.X)
+		b.WriteRune('.')
+		appendExpr(b, t.Sel)
+	case *ast.StarExpr:
+		b.WriteRune('*')
+		appendExpr(b, t.X)
+	case *ast.ArrayType:
+		b.WriteRune('[')
+		appendExpr(b, t.Len)
+		b.WriteRune(']')
+		appendExpr(b, t.Elt)
+	case *ast.StructType:
+		appendStructType(b, t)
+	case *ast.FuncType:
+		b.WriteString("func")
+		appendSignature(b, t)
+	case *ast.InterfaceType:
+		appendInterfaceType(b, t)
+	case *ast.MapType:
+		b.WriteString("map[")
+		appendExpr(b, t.Key)
+		b.WriteString("]")
+		appendExpr(b, t.Value)
+	case *ast.ChanType:
+		appendChanType(b, t)
+	case *ast.BasicLit:
+		b.WriteString(t.Value)
+	case *ast.Ellipsis:
+		b.WriteString("...")
+		appendExpr(b, t.Elt)
+	case *ast.ParenExpr:
+		b.WriteRune('(')
+		appendExpr(b, t.X)
+		b.WriteRune(')')
+	case *ast.IndexExpr:
+		appendExpr(b, t.X)
+		b.WriteRune('[')
+		appendExpr(b, t.Index)
+		b.WriteRune(']')
+	case *ast.IndexListExpr:
+		appendExpr(b, t.X)
+		b.WriteRune('[')
+		for i, index := range t.Indices {
+			if i != 0 {
+				b.WriteString(", ")
+			}
+			appendExpr(b, index)
+		}
+		b.WriteRune(']')
+	default:
+		// Other expressions such as constraints "~int | string" are printed as they are.
+		printer.Fprint(b, token.NewFileSet(), expr)
+	}
+}
+
+// appendSignature renders parameters and results of the function type without "func".
+func appendSignature(b *strings.Builder, t *ast.FuncType) {
+	b.WriteRune('(')
+	if t.Params.NumFields() > 0 {
+		appendFieldList(b, t.Params)
+	}
+	b.WriteRune(')')
+	numResults := t.Results.NumFields()
+	if numResults == 1 {
+		b.WriteRune(' ')
+		appendFieldList(b, t.Results)
+	} else if numResults >= 2 {
+		b.WriteString(" (")
+		appendFieldList(b, t.Results)
+		b.WriteRune(')')
+	}
+}
+
+// appendStructType renders fields in a line such as "struct{A int; B string `json:"b"`}".
+func appendStructType(b *strings.Builder, t *ast.StructType) {
+	b.WriteString("struct{")
+	for i, field := range t.Fields.List {
+		if i != 0 {
+			b.WriteString("; ")
+		}
+		appendNames(b, field.Names)
+		appendExpr(b, field.Type)
+		if field.Tag != nil {
+			b.WriteRune(' ')
+			b.WriteString(field.Tag.Value)
+		}
+	}
+	b.WriteRune('}')
+}
+
+// appendInterfaceType renders methods and embedded types in a line such as "interface{io.Reader; Close() error}".
+func appendInterfaceType(b *strings.Builder, t *ast.InterfaceType) {
+	b.WriteString("interface{")
+	for i, field := range t.Methods.List {
+		if i != 0 {
+			b.WriteString("; ")
+		}
+		if fnType, ok := field.Type.(*ast.FuncType); ok && len(field.Names) > 0 {
+			b.WriteString(field.Names[0].Name)
+			appendSignature(b, fnType)
+		} else {
+			appendExpr(b, field.Type)
+		}
+	}
+	b.WriteRune('}')
+}
+
+func appendChanType(b *strings.Builder, t *ast.ChanType) {
+	if t.Dir == ast.RECV {
+		b.WriteString("<-")
+	}
+	b.WriteString("chan")
+	if t.Dir == ast.SEND {
+		b.WriteString("<-")
+	}
+	b.WriteRune(' ')
+	appendExpr(b, t.Value)
+}
+
+func appendFieldList(b *strings.Builder, expr *ast.FieldList) {
+	for i, field := range expr.List {
+		if i != 0 {
+			b.WriteString(", ")
+		}
+		appendNames(b, field.Names)
+		appendExpr(b, field.Type)
+	}
+}
+
+// appendNames renders names of the field followed by a space if it has names.
+func appendNames(b *strings.Builder, names []*ast.Ident) {
+	if len(names) == 0 {
+		return
+	}
+	for i, name := range names {
+		if i != 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(name.Name)
+	}
+	b.WriteRune(' ')
+}
@@ -0,0 +1,38 @@
+package query
+
+import (
+	"go/parser"
+	"testing"
+)
+
+func TestTypeString(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"int", "int"},
+		{"*pkg.Type", "*pkg.Type"},
+		{"[]map[string][4]int", "[]map[string][4]int"},
+		{"func(a, b int, opts ...string) (int, error)", "func(a, b int, opts ...string) (int, error)"},
+		{"<-chan struct{}", "<-chan struct{}"},
+		{"func()", "func()"},
+		{"struct {\n\tA, B int\n\tio.Reader\n\tC string `json:\"c\"`\n}", "struct{A, B int; io.Reader; C string `json:\"c\"`}"},
+		{"interface {\n\tio.Closer\n\tRead(p []byte) (n int, err error)\n\tString() string\n}", "interface{io.Closer; Read(p []byte) (n int, err error); String() string}"},
+		{"interface{ ~int | ~string }", "interface{~int | ~string}"},
+		{"map[string]interface{}", "map[string]interface{}"},
+		{"List[T]", "List[T]"},
+		{"*pkg.Map[string, []T]", "*pkg.Map[string, []T]"},
+		{"(*int)", "(*int)"},
+		{"~int | string", "~int | string"},
+	}
+
+	for _, tt := range tests {
+		expr, err := parser.ParseExpr(tt.src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := TypeString(expr); got != tt.want {
+			t.Errorf("TypeString(%s) = %s, want %s", tt.src, got, tt.want)
+		}
+	}
+}